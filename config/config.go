@@ -0,0 +1,688 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Config holds every tunable parameter of a simulation.
+type Config struct {
+	World      World              `json:"world"`
+	Grass      Grass              `json:"grass"`
+	Terrain    Terrain            `json:"terrain"`
+	Clock      Clock              `json:"clock"`
+	Outbreak   Outbreak           `json:"outbreak"`
+	Structures Structures         `json:"structures"`
+	Species    map[string]Species `json:"species"`
+}
+
+// Structures are the burrows, dens and other static homes on the board.
+// When the board is populated Counts of each kind are scattered at random
+// and one is built at every place in Places.
+type Structures struct {
+	Counts map[string]int `json:"counts"`
+	Places []Place        `json:"places"`
+}
+
+// Place puts a structure of the given kind at X, Y.
+type Place struct {
+	Kind string  `json:"kind"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// Outbreak infects Count random animals of Species at Tick; a zero Tick
+// means no outbreak.
+type Outbreak struct {
+	Tick    int    `json:"tick"`
+	Species string `json:"species"`
+	Count   int    `json:"count"`
+}
+
+type World struct {
+	Width            int     `json:"width"`
+	Height           int     `json:"height"`
+	GrassSpawnRate   float64 `json:"grassSpawnRate"`
+	MaxGrassFraction float64 `json:"maxGrassFraction"`
+	OffspringSpread  float64 `json:"offspringSpread"`
+	// Boundary decides what happens at the board edges: "clamp" stops
+	// animals at the wall, "reflect" bounces them back and "torus" wraps
+	// them around to the opposite side.
+	Boundary string `json:"boundary"`
+}
+
+const (
+	BoundaryClamp   = "clamp"
+	BoundaryReflect = "reflect"
+	BoundaryTorus   = "torus"
+)
+
+// Grass.Mode values.
+const (
+	GrassEntities = "entities"
+	GrassField    = "field"
+)
+
+type Grass struct {
+	// Mode selects how grass is modelled: "entities" scatters individual
+	// patches over the board, "field" keeps biomass on a grid of cells.
+	Mode string `json:"mode"`
+
+	MaxAmountMin  float64 `json:"maxAmountMin"`
+	MaxAmountMax  float64 `json:"maxAmountMax"`
+	GrowthRateMin float64 `json:"growthRateMin"`
+	GrowthRateMax float64 `json:"growthRateMax"`
+	BiteMin       float64 `json:"biteMin"`
+	BiteMax       float64 `json:"biteMax"`
+
+	// In field mode every cell draws its capacity from the MaxAmount range
+	// and its peak regrowth per tick from the GrowthRate range. CellSize is
+	// the side of a cell in board units; each tick a cell moves Diffusion
+	// of the way towards the mean biomass of its four neighbours.
+	CellSize  float64 `json:"cellSize"`
+	Diffusion float64 `json:"diffusion"`
+}
+
+// Terrain describes the ground under the board. Map is empty for uniform
+// meadow, "noise" to generate terrain from the seed, or the path of a
+// .png or ASCII map, which is stretched over the board.
+type Terrain struct {
+	Map string `json:"map"`
+	// NoiseScale is the typical size of a generated feature in board units.
+	NoiseScale float64      `json:"noiseScale"`
+	Types      TerrainTypes `json:"types"`
+}
+
+// Clock sets the length of a day and of a season in ticks. Night is the
+// second half of every day, and a year runs through the four seasons from
+// spring. A zero DayLength means it is always day, a zero SeasonLength
+// that the seasons change nothing.
+type Clock struct {
+	DayLength    int    `json:"dayLength"`
+	SeasonLength int    `json:"seasonLength"`
+	Spring       Season `json:"spring"`
+	Summer       Season `json:"summer"`
+	Autumn       Season `json:"autumn"`
+	Winter       Season `json:"winter"`
+}
+
+// Season scales grass growth, the grass spawn rate and the energy loss of
+// animals while it lasts.
+type Season struct {
+	GrassGrowth float64 `json:"grassGrowth"`
+	GrassSpawn  float64 `json:"grassSpawn"`
+	EnergyLoss  float64 `json:"energyLoss"`
+}
+
+// TerrainNoise is the Terrain.Map value that generates terrain.
+const TerrainNoise = "noise"
+
+type TerrainTypes struct {
+	Meadow TerrainType `json:"meadow"`
+	Forest TerrainType `json:"forest"`
+	Water  TerrainType `json:"water"`
+	Rock   TerrainType `json:"rock"`
+}
+
+// TerrainType scales movement speed and grass on one kind of ground.
+// Animals never enter ground that is not passable.
+type TerrainType struct {
+	Passable      bool    `json:"passable"`
+	Speed         float64 `json:"speed"`
+	GrassCapacity float64 `json:"grassCapacity"`
+	GrassGrowth   float64 `json:"grassGrowth"`
+}
+
+type Species struct {
+	Energy                  float64  `json:"energy"`
+	MaxEnergy               float64  `json:"maxEnergy"`
+	EnergyLoss              float64  `json:"energyLoss"`
+	CriticalHungerThreshold float64  `json:"criticalHungerThreshold"`
+	SearchRadius            float64  `json:"searchRadius"`
+	MovementSpeed           float64  `json:"movementSpeed"`
+	Diet                    []string `json:"diet"`
+
+	InteractionDistance float64 `json:"interactionDistance"`
+	ReproduceCooldown   int     `json:"reproduceCooldown"`
+	MatingEnergyCost    float64 `json:"matingEnergyCost"`
+
+	// Energy a predator gains from eating this species:
+	// NutritionBase + NutritionFactor * remaining energy of the prey.
+	NutritionBase   float64 `json:"nutritionBase"`
+	NutritionFactor float64 `json:"nutritionFactor"`
+
+	Genetics     Genetics     `json:"genetics"`
+	Flee         Flee         `json:"flee"`
+	Life         Life         `json:"life"`
+	Reproduction Reproduction `json:"reproduction"`
+	Night        Night        `json:"night"`
+	Disease      Disease      `json:"disease"`
+	Group        Group        `json:"group"`
+	Home         Home         `json:"home"`
+	Territory    Territory    `json:"territory"`
+
+	// Brain names the decision policy the species' animals use.
+	Brain string `json:"brain"`
+}
+
+// Life sets how animals of a species age; ages are counted in ticks.
+// Animals are juveniles until MaturityAge and cannot mate before then,
+// adults until SeniorAge and seniors after that; a zero SeniorAge means
+// they never grow old. Animals placed on the board start at MaturityAge.
+type Life struct {
+	MaturityAge int         `json:"maturityAge"`
+	SeniorAge   int         `json:"seniorAge"`
+	Juvenile    StageTraits `json:"juvenile"`
+	Senior      StageTraits `json:"senior"`
+	// SeniorFertility is the chance that a mating involving a senior
+	// produces offspring; it applies once per senior parent.
+	SeniorFertility float64 `json:"seniorFertility"`
+	// The chance of dying of old age in a tick is
+	// MortalityBase * exp(MortalityRate * age).
+	MortalityBase float64 `json:"mortalityBase"`
+	MortalityRate float64 `json:"mortalityRate"`
+}
+
+// Group sets how animals of a species keep together. Animals linked by
+// chains of members within Radius of each other form a group. Herd
+// animals with nothing better to do steer towards their groupmates
+// (Cohesion), away from any closer than Spacing (Separation) and along
+// their mean heading (Alignment). Pack animals hunt together: every member
+// goes for the prey closest to the pack's leader, approaching from its own
+// side at Spacing before closing in. Solitary animals ignore each other.
+type Group struct {
+	Kind       string  `json:"kind"`
+	Radius     float64 `json:"radius"`
+	Spacing    float64 `json:"spacing"`
+	Cohesion   float64 `json:"cohesion"`
+	Separation float64 `json:"separation"`
+	Alignment  float64 `json:"alignment"`
+}
+
+// Home sets where animals of a species live. Structure is the kind of
+// structure they make their home, empty for none: an animal settles in
+// the nearest one it sees and remembers it. Within Radius of its home an
+// animal is inside, where its energy loss is scaled by Rest and, if Hide
+// is set, its predators cannot find it. Animals straying further than
+// Range from home head back; a zero Range lets them roam freely.
+type Home struct {
+	Structure string  `json:"structure"`
+	Radius    float64 `json:"radius"`
+	Range     float64 `json:"range"`
+	Rest      float64 `json:"rest"`
+	Hide      bool    `json:"hide"`
+}
+
+// Territory sets how animals of a species hold ground. A grown animal
+// claims the ground within Radius of its home, or of where it stands, as
+// soon as that spot lies in no other member's territory; young born on the
+// board first move at least Dispersal away from their birthplace. An owner
+// marks its territory whenever it is inside, and a claim left unmarked
+// for MarkLife ticks lapses. Animals keep out of territories not their
+// own, and an owner challenges intruders of its sex: both lose FightCost
+// energy, and an owner left with less energy than the intruder gives up
+// its claim. A zero Radius turns territories off.
+type Territory struct {
+	Radius    float64 `json:"radius"`
+	Dispersal float64 `json:"dispersal"`
+	MarkLife  int     `json:"markLife"`
+	FightCost float64 `json:"fightCost"`
+}
+
+const (
+	GroupSolitary = "solitary"
+	GroupHerd     = "herd"
+	GroupPack     = "pack"
+)
+
+// Disease sets how an infection spreads among animals of a species and
+// what it does to them. Every tick each infected animal passes it to every
+// susceptible one of its species within Radius with probability
+// Transmission. An infected animal loses EnergyLoss times its usual
+// energy and dies with probability Mortality per tick; after Duration
+// ticks it recovers and stays immune for Immunity ticks, or for good if
+// Immunity is 0. A predator eating infected prey catches the disease with
+// probability FromPrey. A zero Duration makes the species immune.
+type Disease struct {
+	Transmission float64 `json:"transmission"`
+	Radius       float64 `json:"radius"`
+	Duration     int     `json:"duration"`
+	EnergyLoss   float64 `json:"energyLoss"`
+	Mortality    float64 `json:"mortality"`
+	Immunity     int     `json:"immunity"`
+	FromPrey     float64 `json:"fromPrey"`
+}
+
+// Night scales an animal's search radius and speed at night.
+type Night struct {
+	SearchRadius float64 `json:"searchRadius"`
+	Speed        float64 `json:"speed"`
+}
+
+// Reproduction sets how animals of a species breed. Every animal is male
+// or female, MaleRatio of them male, and only a male and a female mate.
+// The female then carries a litter of LitterMin to LitterMax young for
+// Gestation ticks and cannot mate again before giving birth. Both parents
+// wait out the species' reproduceCooldown.
+//
+// Every young is born with OffspringEnergy, paid by its parents: the
+// father gives PaternalShare of it at mating, the mother the rest in
+// equal parts over the gestation. A litter is cut down to what both
+// parents can pay for on top of the mating cost, and a mating fails when
+// they cannot pay for a single young.
+type Reproduction struct {
+	MaleRatio float64 `json:"maleRatio"`
+	Gestation int     `json:"gestation"`
+	LitterMin int     `json:"litterMin"`
+	LitterMax int     `json:"litterMax"`
+	// LitterWeights are the relative chances of the litter sizes from
+	// LitterMin to LitterMax; empty means all sizes are equally likely.
+	LitterWeights   []float64 `json:"litterWeights"`
+	OffspringEnergy float64   `json:"offspringEnergy"`
+	PaternalShare   float64   `json:"paternalShare"`
+	// MateChoice is how an animal picks among the mates it sees: the
+	// nearest, the one with most energy, or the one with the highest
+	// value of a genome trait such as "movementSpeed".
+	MateChoice string `json:"mateChoice"`
+}
+
+const (
+	MateNearest = "nearest"
+	MateEnergy  = "energy"
+)
+
+// mateTraits are the genome traits mates can be chosen by.
+var mateTraits = map[string]bool{
+	"movementSpeed":           true,
+	"searchRadius":            true,
+	"energyLoss":              true,
+	"criticalHungerThreshold": true,
+}
+
+// StageTraits scale an animal's speed and energy loss during a life stage,
+// relative to an adult.
+type StageTraits struct {
+	Speed      float64 `json:"speed"`
+	EnergyLoss float64 `json:"energyLoss"`
+}
+
+// Flee controls how prey react to their predators, the species whose
+// diet includes them. A prey that spots a predator within DetectionRadius
+// runs away at PanicSpeed times its movement speed, paying EnergyCost on
+// top of its usual loss for every tick it flees. A zero DetectionRadius
+// turns fleeing off.
+type Flee struct {
+	DetectionRadius float64 `json:"detectionRadius"`
+	PanicSpeed      float64 `json:"panicSpeed"`
+	EnergyCost      float64 `json:"energyCost"`
+}
+
+// Genetics controls how offspring traits vary from their parents'.
+type Genetics struct {
+	// MutationRate is the chance that an inherited trait mutates,
+	// MutationSigma the relative standard deviation of a mutation.
+	MutationRate  float64 `json:"mutationRate"`
+	MutationSigma float64 `json:"mutationSigma"`
+	// Energy loss scales with (speed/default speed)^SpeedCost and
+	// (search radius/default radius)^RadiusCost.
+	SpeedCost  float64 `json:"speedCost"`
+	RadiusCost float64 `json:"radiusCost"`
+}
+
+// Default returns the parameters a run uses where no config file sets
+// them. New features are on by default, so these change as features are
+// added; the README lists how to turn each of them off.
+func Default() *Config {
+	return &Config{
+		World: World{
+			Width:            400,
+			Height:           200,
+			GrassSpawnRate:   0.002,
+			MaxGrassFraction: 0.70,
+			OffspringSpread:  10.0,
+			Boundary:         BoundaryClamp,
+		},
+		Grass: Grass{
+			Mode:          GrassEntities,
+			MaxAmountMin:  50.0,
+			MaxAmountMax:  100.0,
+			GrowthRateMin: 0.5,
+			GrowthRateMax: 1.5,
+			BiteMin:       20.0,
+			BiteMax:       40.0,
+			CellSize:      4.0,
+			Diffusion:     0.05,
+		},
+		Terrain: Terrain{
+			NoiseScale: 80.0,
+			Types: TerrainTypes{
+				Meadow: TerrainType{Passable: true, Speed: 1.0, GrassCapacity: 1.0, GrassGrowth: 1.0},
+				Forest: TerrainType{Passable: true, Speed: 0.6, GrassCapacity: 0.5, GrassGrowth: 0.5},
+				Water:  TerrainType{Passable: false},
+				Rock:   TerrainType{Passable: false},
+			},
+		},
+		Clock: Clock{
+			DayLength:    100,
+			SeasonLength: 750,
+			Spring:       Season{GrassGrowth: 1.2, GrassSpawn: 1.5, EnergyLoss: 1.0},
+			Summer:       Season{GrassGrowth: 1.0, GrassSpawn: 1.0, EnergyLoss: 1.0},
+			Autumn:       Season{GrassGrowth: 0.6, GrassSpawn: 0.5, EnergyLoss: 1.05},
+			Winter:       Season{GrassGrowth: 0.1, GrassSpawn: 0.0, EnergyLoss: 1.2},
+		},
+		Structures: Structures{
+			Counts: map[string]int{"burrow": 6, "den": 2},
+		},
+		Species: registeredSpecies(),
+	}
+}
+
+// registered maps every species known to the simulation to its default
+// parameters; plants are registered with nil defaults.
+var registered = map[string]*Species{}
+
+// RegisterSpecies records a species name and, for animals, the parameters
+// Default starts from. It is called by the entities package on start-up.
+func RegisterSpecies(name string, defaults *Species) {
+	registered[name] = defaults
+}
+
+// brains holds the names of the available animal brains.
+var brains = map[string]bool{}
+
+// RegisterBrain records the name of a brain species may choose. It is
+// called by the entities package on start-up.
+func RegisterBrain(name string) {
+	brains[name] = true
+}
+
+func registeredSpecies() map[string]Species {
+	species := make(map[string]Species)
+	for name, defaults := range registered {
+		if defaults != nil {
+			s := *defaults
+			s.Diet = append([]string(nil), defaults.Diet...)
+			species[name] = s
+		}
+	}
+	return species
+}
+
+// Load reads a JSON config file. Values missing from the file keep their
+// defaults, unknown keys are rejected and the result is validated.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	cfg, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Decode reads a JSON config from r on top of the defaults and validates it.
+// Species listed in the input start from their own defaults, so a file can
+// change a single value without repeating the whole block.
+func Decode(r io.Reader) (*Config, error) {
+	cfg := Default()
+
+	file := struct {
+		World      *World                     `json:"world"`
+		Grass      *Grass                     `json:"grass"`
+		Terrain    *Terrain                   `json:"terrain"`
+		Clock      *Clock                     `json:"clock"`
+		Outbreak   *Outbreak                  `json:"outbreak"`
+		Structures *Structures                `json:"structures"`
+		Species    map[string]json.RawMessage `json:"species"`
+	}{World: &cfg.World, Grass: &cfg.Grass, Terrain: &cfg.Terrain, Clock: &cfg.Clock, Outbreak: &cfg.Outbreak, Structures: &cfg.Structures}
+
+	if err := decodeStrict(r, &file); err != nil {
+		return nil, err
+	}
+
+	for name, data := range file.Species {
+		species := cfg.Species[name]
+		if err := decodeStrict(bytes.NewReader(data), &species); err != nil {
+			return nil, fmt.Errorf("species.%s: %w", name, err)
+		}
+		cfg.Species[name] = species
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func decodeStrict(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Write encodes cfg as indented JSON.
+func (c *Config) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Validate reports every invalid parameter at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.World.Width > 0, "world.width must be positive, got %d", c.World.Width)
+	check(c.World.Height > 0, "world.height must be positive, got %d", c.World.Height)
+	check(c.World.GrassSpawnRate >= 0 && c.World.GrassSpawnRate <= 1,
+		"world.grassSpawnRate must be between 0 and 1, got %g", c.World.GrassSpawnRate)
+	check(c.World.MaxGrassFraction >= 0 && c.World.MaxGrassFraction <= 1,
+		"world.maxGrassFraction must be between 0 and 1, got %g", c.World.MaxGrassFraction)
+	check(c.World.Boundary == BoundaryClamp || c.World.Boundary == BoundaryReflect || c.World.Boundary == BoundaryTorus,
+		"world.boundary must be %q, %q or %q, got %q", BoundaryClamp, BoundaryReflect, BoundaryTorus, c.World.Boundary)
+	check(c.World.OffspringSpread >= 0, "world.offspringSpread must not be negative, got %g", c.World.OffspringSpread)
+
+	check(c.Grass.Mode == GrassEntities || c.Grass.Mode == GrassField,
+		"grass.mode must be %q or %q, got %q", GrassEntities, GrassField, c.Grass.Mode)
+	check(c.Grass.MaxAmountMin > 0, "grass.maxAmountMin must be positive, got %g", c.Grass.MaxAmountMin)
+	check(c.Grass.MaxAmountMax >= c.Grass.MaxAmountMin,
+		"grass.maxAmountMax (%g) must not be below grass.maxAmountMin (%g)", c.Grass.MaxAmountMax, c.Grass.MaxAmountMin)
+	check(c.Grass.GrowthRateMin >= 0, "grass.growthRateMin must not be negative, got %g", c.Grass.GrowthRateMin)
+	check(c.Grass.GrowthRateMax >= c.Grass.GrowthRateMin,
+		"grass.growthRateMax (%g) must not be below grass.growthRateMin (%g)", c.Grass.GrowthRateMax, c.Grass.GrowthRateMin)
+	check(c.Grass.BiteMin > 0, "grass.biteMin must be positive, got %g", c.Grass.BiteMin)
+	check(c.Grass.BiteMax >= c.Grass.BiteMin,
+		"grass.biteMax (%g) must not be below grass.biteMin (%g)", c.Grass.BiteMax, c.Grass.BiteMin)
+	check(c.Grass.CellSize > 0, "grass.cellSize must be positive, got %g", c.Grass.CellSize)
+	check(c.Grass.Diffusion >= 0 && c.Grass.Diffusion <= 1,
+		"grass.diffusion must be between 0 and 1, got %g", c.Grass.Diffusion)
+
+	check(c.Terrain.NoiseScale > 0, "terrain.noiseScale must be positive, got %g", c.Terrain.NoiseScale)
+	for _, t := range []struct {
+		name string
+		t    TerrainType
+	}{
+		{"meadow", c.Terrain.Types.Meadow},
+		{"forest", c.Terrain.Types.Forest},
+		{"water", c.Terrain.Types.Water},
+		{"rock", c.Terrain.Types.Rock},
+	} {
+		prefix := "terrain.types." + t.name
+		check(!t.t.Passable || t.t.Speed > 0, "%s.speed must be positive on passable ground, got %g", prefix, t.t.Speed)
+		check(t.t.Speed >= 0, "%s.speed must not be negative, got %g", prefix, t.t.Speed)
+		check(t.t.GrassCapacity >= 0, "%s.grassCapacity must not be negative, got %g", prefix, t.t.GrassCapacity)
+		check(t.t.GrassGrowth >= 0, "%s.grassGrowth must not be negative, got %g", prefix, t.t.GrassGrowth)
+	}
+
+	check(c.Clock.DayLength >= 0, "clock.dayLength must not be negative, got %d", c.Clock.DayLength)
+	check(c.Clock.SeasonLength >= 0, "clock.seasonLength must not be negative, got %d", c.Clock.SeasonLength)
+	for _, s := range []struct {
+		name string
+		s    Season
+	}{
+		{"spring", c.Clock.Spring},
+		{"summer", c.Clock.Summer},
+		{"autumn", c.Clock.Autumn},
+		{"winter", c.Clock.Winter},
+	} {
+		prefix := "clock." + s.name
+		check(s.s.GrassGrowth >= 0, "%s.grassGrowth must not be negative, got %g", prefix, s.s.GrassGrowth)
+		check(s.s.GrassSpawn >= 0, "%s.grassSpawn must not be negative, got %g", prefix, s.s.GrassSpawn)
+		check(s.s.EnergyLoss >= 0, "%s.energyLoss must not be negative, got %g", prefix, s.s.EnergyLoss)
+	}
+
+	if c.Outbreak.Tick != 0 {
+		check(c.Outbreak.Tick > 0, "outbreak.tick must not be negative, got %d", c.Outbreak.Tick)
+		_, ok := c.Species[c.Outbreak.Species]
+		check(ok, "outbreak.species: %q is not a configured species", c.Outbreak.Species)
+		check(c.Outbreak.Count > 0, "outbreak.count must be positive, got %d", c.Outbreak.Count)
+	}
+
+	// A structure is a registered species without animal parameters.
+	isStructure := func(kind string) bool {
+		_, known := registered[kind]
+		_, animal := c.Species[kind]
+		return known && !animal
+	}
+	kinds := make([]string, 0, len(c.Structures.Counts))
+	for kind := range c.Structures.Counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		check(isStructure(kind), "structures.counts: %q is not a structure", kind)
+		check(c.Structures.Counts[kind] >= 0, "structures.counts.%s must not be negative, got %d", kind, c.Structures.Counts[kind])
+	}
+	for i, p := range c.Structures.Places {
+		check(isStructure(p.Kind), "structures.places[%d].kind: %q is not a structure", i, p.Kind)
+		check(p.X >= 0 && p.X < float64(c.World.Width) && p.Y >= 0 && p.Y < float64(c.World.Height),
+			"structures.places[%d] at (%g, %g) is off the board", i, p.X, p.Y)
+	}
+
+	names := make([]string, 0, len(c.Species))
+	for name := range c.Species {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := c.Species[name]
+		prefix := fmt.Sprintf("species.%s", name)
+		_, known := registered[name]
+		check(known, "%s is not a registered species", prefix)
+		check(s.MaxEnergy > 0, "%s.maxEnergy must be positive, got %g", prefix, s.MaxEnergy)
+		check(s.Energy > 0 && s.Energy <= s.MaxEnergy,
+			"%s.energy must be in (0, maxEnergy=%g], got %g", prefix, s.MaxEnergy, s.Energy)
+		check(s.EnergyLoss >= 0, "%s.energyLoss must not be negative, got %g", prefix, s.EnergyLoss)
+		check(s.CriticalHungerThreshold >= 0, "%s.criticalHungerThreshold must not be negative, got %g", prefix, s.CriticalHungerThreshold)
+		check(s.SearchRadius > 0, "%s.searchRadius must be positive, got %g", prefix, s.SearchRadius)
+		check(s.MovementSpeed >= 0, "%s.movementSpeed must not be negative, got %g", prefix, s.MovementSpeed)
+		check(s.InteractionDistance > 0, "%s.interactionDistance must be positive, got %g", prefix, s.InteractionDistance)
+		check(s.ReproduceCooldown >= 0, "%s.reproduceCooldown must not be negative, got %d", prefix, s.ReproduceCooldown)
+		check(s.MatingEnergyCost >= 0, "%s.matingEnergyCost must not be negative, got %g", prefix, s.MatingEnergyCost)
+		check(s.NutritionBase >= 0, "%s.nutritionBase must not be negative, got %g", prefix, s.NutritionBase)
+		check(s.NutritionFactor >= 0, "%s.nutritionFactor must not be negative, got %g", prefix, s.NutritionFactor)
+
+		check(s.Genetics.MutationRate >= 0 && s.Genetics.MutationRate <= 1,
+			"%s.genetics.mutationRate must be between 0 and 1, got %g", prefix, s.Genetics.MutationRate)
+		check(s.Genetics.MutationSigma >= 0, "%s.genetics.mutationSigma must not be negative, got %g", prefix, s.Genetics.MutationSigma)
+		check(s.Genetics.SpeedCost >= 0, "%s.genetics.speedCost must not be negative, got %g", prefix, s.Genetics.SpeedCost)
+		check(s.Genetics.RadiusCost >= 0, "%s.genetics.radiusCost must not be negative, got %g", prefix, s.Genetics.RadiusCost)
+		check(s.Flee.DetectionRadius >= 0, "%s.flee.detectionRadius must not be negative, got %g", prefix, s.Flee.DetectionRadius)
+		check(s.Flee.DetectionRadius == 0 || s.Flee.PanicSpeed > 0,
+			"%s.flee.panicSpeed must be positive when fleeing is on, got %g", prefix, s.Flee.PanicSpeed)
+		check(s.Flee.EnergyCost >= 0, "%s.flee.energyCost must not be negative, got %g", prefix, s.Flee.EnergyCost)
+		check(s.Life.MaturityAge >= 0, "%s.life.maturityAge must not be negative, got %d", prefix, s.Life.MaturityAge)
+		check(s.Life.SeniorAge == 0 || s.Life.SeniorAge >= s.Life.MaturityAge,
+			"%s.life.seniorAge (%d) must be 0 or not below life.maturityAge (%d)", prefix, s.Life.SeniorAge, s.Life.MaturityAge)
+		check(s.Life.Juvenile.Speed > 0, "%s.life.juvenile.speed must be positive, got %g", prefix, s.Life.Juvenile.Speed)
+		check(s.Life.Juvenile.EnergyLoss >= 0, "%s.life.juvenile.energyLoss must not be negative, got %g", prefix, s.Life.Juvenile.EnergyLoss)
+		check(s.Life.Senior.Speed > 0, "%s.life.senior.speed must be positive, got %g", prefix, s.Life.Senior.Speed)
+		check(s.Life.Senior.EnergyLoss >= 0, "%s.life.senior.energyLoss must not be negative, got %g", prefix, s.Life.Senior.EnergyLoss)
+		check(s.Life.SeniorFertility >= 0 && s.Life.SeniorFertility <= 1,
+			"%s.life.seniorFertility must be between 0 and 1, got %g", prefix, s.Life.SeniorFertility)
+		check(s.Life.MortalityBase >= 0 && s.Life.MortalityBase <= 1,
+			"%s.life.mortalityBase must be between 0 and 1, got %g", prefix, s.Life.MortalityBase)
+		check(s.Life.MortalityRate >= 0, "%s.life.mortalityRate must not be negative, got %g", prefix, s.Life.MortalityRate)
+		check(s.Night.SearchRadius > 0, "%s.night.searchRadius must be positive, got %g", prefix, s.Night.SearchRadius)
+		check(s.Night.Speed > 0, "%s.night.speed must be positive, got %g", prefix, s.Night.Speed)
+		g := s.Group
+		check(g.Kind == "" || g.Kind == GroupSolitary || g.Kind == GroupHerd || g.Kind == GroupPack,
+			"%s.group.kind must be %q, %q or %q, got %q", prefix, GroupSolitary, GroupHerd, GroupPack, g.Kind)
+		check(g.Radius >= 0, "%s.group.radius must not be negative, got %g", prefix, g.Radius)
+		check(g.Kind != GroupHerd && g.Kind != GroupPack || g.Radius > 0,
+			"%s.group.radius must be positive for a %s", prefix, g.Kind)
+		check(g.Spacing >= 0, "%s.group.spacing must not be negative, got %g", prefix, g.Spacing)
+		check(g.Cohesion >= 0 && g.Separation >= 0 && g.Alignment >= 0,
+			"%s.group weights must not be negative, got cohesion %g, separation %g, alignment %g",
+			prefix, g.Cohesion, g.Separation, g.Alignment)
+		h := s.Home
+		check(h.Structure == "" || isStructure(h.Structure), "%s.home.structure: %q is not a structure", prefix, h.Structure)
+		check(h.Radius >= 0, "%s.home.radius must not be negative, got %g", prefix, h.Radius)
+		check(h.Structure == "" || h.Radius > 0, "%s.home.radius must be positive with a home structure", prefix)
+		check(h.Range >= 0, "%s.home.range must not be negative, got %g", prefix, h.Range)
+		check(h.Rest >= 0, "%s.home.rest must not be negative, got %g", prefix, h.Rest)
+		t := s.Territory
+		check(t.Radius >= 0, "%s.territory.radius must not be negative, got %g", prefix, t.Radius)
+		check(t.Dispersal >= 0, "%s.territory.dispersal must not be negative, got %g", prefix, t.Dispersal)
+		check(t.Radius == 0 || t.MarkLife > 0, "%s.territory.markLife must be positive with territories, got %d", prefix, t.MarkLife)
+		check(t.FightCost >= 0, "%s.territory.fightCost must not be negative, got %g", prefix, t.FightCost)
+		d := s.Disease
+		check(d.Transmission >= 0 && d.Transmission <= 1,
+			"%s.disease.transmission must be between 0 and 1, got %g", prefix, d.Transmission)
+		check(d.Radius >= 0, "%s.disease.radius must not be negative, got %g", prefix, d.Radius)
+		check(d.Duration >= 0, "%s.disease.duration must not be negative, got %d", prefix, d.Duration)
+		check(d.EnergyLoss >= 0, "%s.disease.energyLoss must not be negative, got %g", prefix, d.EnergyLoss)
+		check(d.Mortality >= 0 && d.Mortality <= 1, "%s.disease.mortality must be between 0 and 1, got %g", prefix, d.Mortality)
+		check(d.Immunity >= 0, "%s.disease.immunity must not be negative, got %d", prefix, d.Immunity)
+		check(d.FromPrey >= 0 && d.FromPrey <= 1, "%s.disease.fromPrey must be between 0 and 1, got %g", prefix, d.FromPrey)
+		r := s.Reproduction
+		check(r.MaleRatio > 0 && r.MaleRatio < 1, "%s.reproduction.maleRatio must be between 0 and 1, got %g", prefix, r.MaleRatio)
+		check(r.Gestation >= 0, "%s.reproduction.gestation must not be negative, got %d", prefix, r.Gestation)
+		check(r.LitterMin >= 1, "%s.reproduction.litterMin must be at least 1, got %d", prefix, r.LitterMin)
+		check(r.LitterMax >= r.LitterMin,
+			"%s.reproduction.litterMax (%d) must not be below reproduction.litterMin (%d)", prefix, r.LitterMax, r.LitterMin)
+		if len(r.LitterWeights) > 0 {
+			check(len(r.LitterWeights) == r.LitterMax-r.LitterMin+1,
+				"%s.reproduction.litterWeights must have one weight per litter size from %d to %d, got %d",
+				prefix, r.LitterMin, r.LitterMax, len(r.LitterWeights))
+			total := 0.0
+			for _, weight := range r.LitterWeights {
+				check(weight >= 0, "%s.reproduction.litterWeights must not be negative, got %g", prefix, weight)
+				total += weight
+			}
+			check(total > 0, "%s.reproduction.litterWeights must not all be 0", prefix)
+		}
+		check(r.OffspringEnergy > 0 && r.OffspringEnergy <= s.MaxEnergy,
+			"%s.reproduction.offspringEnergy must be positive and at most maxEnergy (%g), got %g", prefix, s.MaxEnergy, r.OffspringEnergy)
+		check(r.PaternalShare >= 0 && r.PaternalShare <= 1,
+			"%s.reproduction.paternalShare must be between 0 and 1, got %g", prefix, r.PaternalShare)
+		check(r.MateChoice == "" || r.MateChoice == MateNearest || r.MateChoice == MateEnergy || mateTraits[r.MateChoice],
+			"%s.reproduction.mateChoice: %q is not nearest, energy or a genome trait", prefix, r.MateChoice)
+		check(s.Brain == "" || brains[s.Brain], "%s.brain: %q is not a known brain", prefix, s.Brain)
+		check(len(s.Diet) > 0, "%s.diet must list at least one food species", prefix)
+		for _, food := range s.Diet {
+			_, configured := c.Species[food]
+			_, known := registered[food]
+			check(configured || known, "%s.diet: %q is not a known species", prefix, food)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,39 +2,157 @@ package entities
 
 import (
 	"math"
-	"math/rand"
+	"math/rand/v2"
 
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
 	"github.com/j-bisew/foxes-rabbits-simulation/geom"
 	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
 )
 type Entity = interfaces.Entity
 type WorldInterface = interfaces.WorldInterface
+type Cause = interfaces.Cause
 
 type Animal struct {
+	ID uint64
 	Pos geom.Point
 	Energy, MaxEnergy, EnergyLoss float64
 	CriticalHungerThreshold float64
 	ReproduceCooldown int
 	SearchRadius float64
 	Species string
-	FoodType string
+	Diet []string
 	MovementSpeed float64
 	Alive bool
+	DeathCause Cause
+
+	InteractionDistance float64
+	MatingCooldown int
+	MatingEnergyCost float64
+
+	DetectionRadius float64
+	PanicSpeed float64
+	FleeCost float64
+
+	Age int
+	Life config.Life
+
+	Sex Sex
+	Reproduction config.Reproduction
+	Pregnancy *Pregnancy
+
+	Night config.Night
+
+	Health Health
+	HealthTimer int
+	Disease config.Disease
+
+	Group config.Group
+	// Heading is the animal's last step, zero if it did not move.
+	Heading geom.Point
+
+	Home config.Home
+	// Nest is where the animal lives, nil until it finds a home.
+	Nest *geom.Point
+	Sheltered bool
+
+	Territory config.Territory
+	// Claim is the centre of the animal's territory, nil while it holds
+	// none; Marked counts down the ticks until its mark fades.
+	Claim *geom.Point
+	Marked int
+	// Birthplace is nil for animals placed on the board.
+	Birthplace *geom.Point
+	// Routed is set when the animal loses a fight as an intruder, until
+	// it is out of the territories of others.
+	Routed bool
+
+	Genome Genome
+	Brain Brain `json:"-"`
 } 
 
+func newAnimal(species string, x, y float64, cfg config.Species, rng *rand.Rand) Animal {
+	a := Animal{
+		Pos: geom.Point{X: x, Y: y},
+		Energy: cfg.Energy,
+		MaxEnergy: cfg.MaxEnergy,
+		EnergyLoss: cfg.EnergyLoss,
+		CriticalHungerThreshold: cfg.CriticalHungerThreshold,
+		ReproduceCooldown: 0,
+		SearchRadius: cfg.SearchRadius,
+		Species: species,
+		Diet: append([]string(nil), cfg.Diet...),
+		MovementSpeed: cfg.MovementSpeed,
+		Alive: true,
+		InteractionDistance: cfg.InteractionDistance,
+		MatingCooldown: cfg.ReproduceCooldown,
+		MatingEnergyCost: cfg.MatingEnergyCost,
+		DetectionRadius: cfg.Flee.DetectionRadius,
+		PanicSpeed: cfg.Flee.PanicSpeed,
+		FleeCost: cfg.Flee.EnergyCost,
+		Age: cfg.Life.MaturityAge,
+		Life: cfg.Life,
+		Sex: randomSex(rng, cfg.Reproduction.MaleRatio),
+		Reproduction: cfg.Reproduction,
+		Night: cfg.Night,
+		Disease: cfg.Disease,
+		Group: cfg.Group,
+		Home: cfg.Home,
+		Territory: cfg.Territory,
+		Brain: NewBrain(cfg.Brain),
+	}
+	a.Express(DefaultGenome(cfg), cfg)
+	return a
+}
+
+// Genetics
+func (a *Animal) GetGenome() Genome { return a.Genome }
+
+// Express sets the animal's traits from g, including the energy cost of them.
+func (a *Animal) Express(g Genome, cfg config.Species) {
+	a.Genome = g
+	a.MovementSpeed = g.MovementSpeed
+	a.SearchRadius = g.SearchRadius
+	a.CriticalHungerThreshold = g.CriticalHungerThreshold
+	a.EnergyLoss = MetabolicCost(g, cfg)
+}
+
 // Getters
+func (a *Animal) GetID() uint64 { return a.ID }
+func (a *Animal) SetID(id uint64) { a.ID = id }
 func (a *Animal) GetPosition() geom.Point { return a.Pos }
 func (a *Animal) GetEnergy() float64 { return a.Energy }
 func (a *Animal) GetSpecies() string { return a.Species }
-func (a *Animal) GetFoodType() string { return a.FoodType }
+func (a *Animal) GetDiet() []string { return a.Diet }
 func (a *Animal) IsAlive() bool { return a.Alive }
-func (a *Animal) Kill() { a.Alive = false }
-
+func (a *Animal) GetDeathCause() Cause { return a.DeathCause }
+func (a *Animal) Kill(cause Cause) {
+	if a.Alive {
+		a.Alive = false
+		a.DeathCause = cause
+	}
+}
 
 // Hunger & Reproduction
-func (a *Animal) ConsumeEnergy() { a.Energy -= a.EnergyLoss }
 
-func (a *Animal) CanReproduce() bool { return a.ReproduceCooldown == 0 }
+// ConsumeEnergy pays the animal's upkeep for the tick, moves what a
+// pregnant female spends on her litter into its provision, and books
+// each part with the world.
+func (a *Animal) ConsumeEnergy(world WorldInterface) {
+	metabolism := a.metabolism(world)
+	sickness := metabolism*a.sicknessLoss() - metabolism
+	gestation := a.gestationCost()
+	if a.Pregnancy != nil {
+		a.Pregnancy.Provision += gestation
+	}
+	a.Energy -= metabolism + sickness + gestation
+	world.Charge(interfaces.Upkeep, metabolism)
+	world.Charge(interfaces.Sickness, sickness)
+	world.Charge(interfaces.Gestation, gestation)
+}
+
+func (a *Animal) CanReproduce() bool {
+	return a.ReproduceCooldown == 0 && a.Stage() != Juvenile && a.Pregnancy == nil
+}
 func (a *Animal) UpdateReproduce() { 
     if a.ReproduceCooldown > 0 {
         a.ReproduceCooldown--
@@ -46,110 +164,202 @@ func (a *Animal) UpdateEnergy(amount float64) {
     }
 }
 
+func (a *Animal) Feed(energy float64) {
+	a.Energy += energy
+	if a.Energy > a.MaxEnergy { a.Energy = a.MaxEnergy }
+}
+
+func (a *Animal) Mated() {
+	a.ReproduceCooldown = a.MatingCooldown
+	a.UpdateEnergy(-a.MatingEnergyCost)
+}
+
 // Movement & Search
-func (a *Animal) Move(dx, dy float64) {
-	a.Pos.X += dx
-	a.Pos.Y += dy
+func (a *Animal) Move(world WorldInterface, dx, dy float64) {
+	a.Heading = geom.Point{}
+	next := world.Confine(geom.Point{X: a.Pos.X + dx, Y: a.Pos.Y + dy})
+	if world.Mobility(next) == 0 {
+		return
+	}
+	a.Pos = next
+	a.Heading = geom.Point{X: dx, Y: dy}
+}
+
+func (a *Animal) GetHeading() geom.Point { return a.Heading }
+
+// speed is how far the animal gets in one step on its current ground.
+func (a *Animal) speed(world WorldInterface) float64 {
+	speed := a.MovementSpeed * a.stageTraits().Speed
+	if world.IsNight() {
+		speed *= a.Night.Speed
+	}
+	if mobility := world.Mobility(a.Pos); mobility > 0 {
+		return speed * mobility
+	}
+	return speed
+}
+
+// canStep reports whether a step ends, and passes its midpoint, on
+// passable ground, so a long stride does not hop over a narrow river.
+func (a *Animal) canStep(world WorldInterface, dx, dy float64) bool {
+	mid := world.Confine(geom.Point{X: a.Pos.X + dx/2, Y: a.Pos.Y + dy/2})
+	end := world.Confine(geom.Point{X: a.Pos.X + dx, Y: a.Pos.Y + dy})
+	return world.Mobility(mid) > 0 && world.Mobility(end) > 0
 }
 
-func (a *Animal) MoveRandomly() {
-	angle := rand.Float64() * 2 * math.Pi
-	dx := math.Cos(angle) * a.MovementSpeed
-	dy := math.Sin(angle) * a.MovementSpeed
-	a.Move(dx, dy)
+// randomStep picks a random direction that does not run into impassable
+// ground; an animal boxed in on all tries stays where it is.
+func (a *Animal) randomStep(world WorldInterface) (float64, float64) {
+	speed := a.speed(world)
+	for try := 0; try < 8; try++ {
+		angle := world.Random().Float64() * 2 * math.Pi
+		dx, dy := math.Cos(angle)*speed, math.Sin(angle)*speed
+		if a.canStep(world, dx, dy) {
+			return dx, dy
+		}
+	}
+	return 0, 0
 }
 
-func (a *Animal) DistanceTo(target geom.Point) (float64, float64, float64) {
-	dx := target.X - a.Pos.X
-	dy := target.Y - a.Pos.Y
+func (a *Animal) DistanceTo(world WorldInterface, target geom.Point) (float64, float64, float64) {
+	dx, dy := world.Offset(a.Pos, target)
 	
 	return dx, dy, math.Sqrt(dx*dx + dy*dy)
 }
 
-func (a *Animal) MoveTowards(target geom.Point) {
-	dx, dy, distance := a.DistanceTo(target)
-	if distance > 0 {
-		dx = (dx / distance) * a.MovementSpeed
-		dy = (dy / distance) * a.MovementSpeed
-		a.Move(dx, dy)
+// stepTowards heads straight for target and wanders off randomly when the
+// way is blocked.
+func (a *Animal) stepTowards(world WorldInterface, target geom.Point) (float64, float64) {
+	dx, dy, distance := a.DistanceTo(world, target)
+	if distance == 0 {
+		return 0, 0
+	}
+	speed := a.speed(world)
+	dx, dy = (dx/distance)*speed, (dy/distance)*speed
+	if !a.canStep(world, dx, dy) {
+		return a.randomStep(world)
 	}
+	return dx, dy
 }
 
-func (a *Animal) findClosest(entities []Entity) Entity {
-	if len(entities) == 0 {
-		return nil
-	}
+// carryOut turns a brain's intent into this tick's action.
+func (a *Animal) carryOut(world WorldInterface, intent Intent) interfaces.Action {
+	switch intent.Kind {
+	case Wander:
+		dx, dy := a.randomStep(world)
+		return interfaces.Action{DX: dx, DY: dy}
 
-	closest := entities[0]
-	_,_,minDistance := a.DistanceTo(closest.GetPosition())
+	case Walk:
+		norm := math.Sqrt(intent.DX*intent.DX + intent.DY*intent.DY)
+		if norm == 0 {
+			return interfaces.Action{}
+		}
+		speed := a.speed(world)
+		dx, dy := intent.DX/norm*speed, intent.DY/norm*speed
+		if !a.canStep(world, dx, dy) {
+			dx, dy = a.randomStep(world)
+		}
+		return interfaces.Action{DX: dx, DY: dy}
 
-	for _, entity := range entities[1:] {
-		_,_,distance := a.DistanceTo(entity.GetPosition())
-		if distance < minDistance {
-			minDistance = distance
-			closest = entity
+	case Return, Hide:
+		speed := a.speed(world)
+		if intent.Kind == Hide {
+			speed *= a.PanicSpeed
 		}
-	}
+		dx, dy := intent.Target.DX, intent.Target.DY
+		if distance := intent.Target.Distance; distance > speed {
+			dx, dy = dx/distance*speed, dy/distance*speed
+		}
+		if !a.canStep(world, dx, dy) {
+			dx, dy = a.randomStep(world)
+		}
+		action := interfaces.Action{DX: dx, DY: dy}
+		if intent.Kind == Hide {
+			action.Kind = interfaces.Flee
+		}
+		return action
 
-	return closest
-}
-
-func (a *Animal) search(world WorldInterface, searchType string) {
-    var targets []Entity
-    if searchType == "food" {
-        targets = world.FindNearbyEntities(a.Pos, a.SearchRadius, a.FoodType)
-	} else if searchType == "mate" {
-		nearby := world.FindNearbyEntities(a.Pos, a.SearchRadius, a.Species)
-		
-		for _, entity := range nearby {
-			if entity != Entity(a) && entity.IsAlive() {
-				if rabbit, ok := entity.(*Rabbit); ok && rabbit.ReproduceCooldown == 0 {
-					targets = append(targets, entity)
-				} else if fox, ok := entity.(*Fox); ok && fox.ReproduceCooldown == 0 {
-					targets = append(targets, entity)
-				}
+	case Escape:
+		action := interfaces.Action{Kind: interfaces.Flee}
+		norm := math.Sqrt(intent.DX*intent.DX + intent.DY*intent.DY)
+		if norm > 0 {
+			speed := a.speed(world) * a.PanicSpeed
+			if dx, dy := intent.DX/norm*speed, intent.DY/norm*speed; a.canStep(world, dx, dy) {
+				action.DX, action.DY = dx, dy
+				return action
 			}
 		}
-	}
-	
-	if len(targets) > 0 {
-		closest := a.findClosest(targets)
-		a.MoveTowards(closest.GetPosition())
-
-		_, _, distance := a.DistanceTo(closest.GetPosition())
-		if distance < 5.0 {
-			if searchType == "food" {
-				energyGained := world.ConsumeFood(closest, Entity(a))
-				a.Energy += energyGained
-				if a.Energy > a.MaxEnergy { a.Energy = a.MaxEnergy }
-			} else if searchType == "mate" {
-				world.CreateOffspring(Entity(a), closest)
-				a.ReproduceCooldown = 40
-				a.UpdateEnergy(-10.0)
-				closest.UpdateEnergy(-10.0)
+		// Cornered or surrounded evenly: bolt in any open direction,
+		// at normal speed if the panicked step would not be open.
+		dx, dy := a.randomStep(world)
+		if a.canStep(world, dx*a.PanicSpeed, dy*a.PanicSpeed) {
+			dx, dy = dx*a.PanicSpeed, dy*a.PanicSpeed
+		}
+		action.DX, action.DY = dx, dy
+		return action
+
+	case Forage, Court, Challenge:
+		target := intent.Target.Position
+		dx, dy := a.stepTowards(world, target)
+		action := interfaces.Action{DX: dx, DY: dy}
+
+		moved := world.Confine(geom.Point{X: a.Pos.X + dx, Y: a.Pos.Y + dy})
+		ox, oy := world.Offset(moved, target)
+		if distance := math.Sqrt(ox*ox + oy*oy); distance < a.InteractionDistance {
+			action.Distance = distance
+			switch {
+			case intent.Kind == Court && intent.Target.Entity != nil:
+				action.Kind, action.Target = interfaces.Mate, intent.Target.Entity
+			case intent.Kind == Challenge && intent.Target.Entity != nil:
+				action.Kind, action.Target = interfaces.Fight, intent.Target.Entity
+			case intent.Kind == Forage && intent.Target.Entity != nil:
+				action.Kind, action.Target = interfaces.Eat, intent.Target.Entity
+			case intent.Kind == Forage:
+				action.Kind, action.At = interfaces.Graze, target
 			}
 		}
-	} else {
-		a.MoveRandomly()
+		return action
 	}
+	return interfaces.Action{}
 }
 
 // Main Behavior
-func (a *Animal) Update(world WorldInterface) {
-    a.ConsumeEnergy()
+
+// Decide asks the animal's brain what to do, given what the animal
+// perceives. An animal that will starve in this tick's upkeep does nothing.
+func (a *Animal) Decide(world WorldInterface) interfaces.Action {
+	p := a.perceive(world)
+	if p.Energy <= 0 {
+		return interfaces.Action{}
+	}
+
+	return a.carryOut(world, a.Brain.Decide(p, world.Random()))
+}
+
+func (a *Animal) Act(world WorldInterface, action interfaces.Action) {
+    a.ConsumeEnergy(world)
+    if action.Kind == interfaces.Flee && a.Energy > 0 {
+        a.UpdateEnergy(-a.FleeCost)
+        world.Charge(interfaces.Fleeing, a.FleeCost)
+    }
 	
     if a.Energy <= 0 {
-        a.Alive = false
+        a.Kill(interfaces.Starvation)
         return
     }
 
+	a.Age++
+	if p := a.mortality(); p > 0 && world.Random().Float64() < p {
+		a.Kill(interfaces.OldAge)
+		return
+	}
+	if a.progressDisease(world) {
+		return
+	}
+
 	a.UpdateReproduce()
-    
-    if a.Energy < a.CriticalHungerThreshold {
-        a.search(world, "food")
-    } else if a.ReproduceCooldown == 0 {
-        a.search(world, "mate")
-    } else {
-        a.search(world, "food")
-    }
+	a.gestate()
+	a.Move(world, action.DX, action.DY)
+	a.settle(world)
+	a.holdTerritory(world)
 }
@@ -0,0 +1,222 @@
+package entities
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+// Brain chooses what an animal does each tick. Brains run concurrently
+// for different animals during the sense phase, so a brain may keep state
+// of its own animal but must not share it with others; rng is the
+// animal's random stream for the tick.
+type Brain interface {
+	Decide(p *Perception, rng *rand.Rand) Intent
+}
+
+type IntentKind int
+
+const (
+	// Rest stays in place.
+	Rest IntentKind = iota
+	// Wander takes a step in a random open direction.
+	Wander
+	// Walk steps along DX, DY at normal speed.
+	Walk
+	// Escape runs along DX, DY at panic speed, paying the flee cost; with
+	// a zero direction it bolts any open way.
+	Escape
+	// Forage heads for Target and eats it once in reach.
+	Forage
+	// Court heads for Target and mates with it once in reach.
+	Court
+	// Return walks to Target, the animal's home, stopping there.
+	Return
+	// Hide runs home like Return at panic speed, paying the flee cost.
+	Hide
+	// Challenge heads for Target and fights it once in reach.
+	Challenge
+)
+
+// Intent is a brain's choice; the animal turns it into an action, taking
+// care of its speed, the terrain and whether the target is in reach.
+type Intent struct {
+	Kind   IntentKind
+	DX, DY float64
+	Target Sighting
+}
+
+// DefaultBrain is the name of the brain animals use unless configured
+// otherwise.
+const DefaultBrain = "default"
+
+var brains = map[string]func() Brain{}
+
+// RegisterBrain makes a brain available to species configs by name;
+// newBrain is called once for every animal.
+func RegisterBrain(name string, newBrain func() Brain) {
+	brains[name] = newBrain
+	config.RegisterBrain(name)
+}
+
+// NewBrain returns a new brain of the named kind, or the default brain
+// for an empty or unknown name.
+func NewBrain(name string) Brain {
+	if newBrain, ok := brains[name]; ok {
+		return newBrain()
+	}
+	return brains[DefaultBrain]()
+}
+
+// Thinker is implemented by entities that decide with a Brain, which is
+// not part of their saved state.
+type Thinker interface {
+	SetBrain(b Brain)
+}
+
+func (a *Animal) SetBrain(b Brain) { a.Brain = b }
+
+// BrainNames lists the registered brains in name order.
+func BrainNames() []string {
+	names := make([]string, 0, len(brains))
+	for name := range brains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// forager is the classic behaviour: flee from any predator in sight,
+// otherwise look for food when hungry and for a mate when grown up and
+// ready, and roam when nothing is found. Pack animals hunt with their
+// pack, herd animals roam with their herd. Animals that hide at home
+// flee there, and pregnant females go home to give birth. Territory
+// owners that are not hungry drive out intruders, and grown animals
+// keep out of the territories of others, at once when an owner has
+// beaten them, and the owner lets them go.
+type forager struct{}
+
+func (forager) Decide(p *Perception, rng *rand.Rand) Intent {
+	if threats := p.Threats(); len(threats) > 0 {
+		if nest, ok := p.Nest(); ok && p.Home.Hide && nest.Distance <= p.SearchRadius {
+			if p.Sheltered {
+				return Intent{Kind: Rest}
+			}
+			return Intent{Kind: Hide, Target: nest}
+		}
+		// Every predator pushes directly away from itself, nearer ones harder.
+		var ex, ey float64
+		for _, t := range threats {
+			d2 := t.DX*t.DX + t.DY*t.DY
+			if d2 == 0 {
+				continue
+			}
+			ex -= t.DX / d2
+			ey -= t.DY / d2
+		}
+		return Intent{Kind: Escape, DX: ex, DY: ey}
+	}
+
+	if p.Routed {
+		if intent, ok := disperse(p); ok {
+			return intent
+		}
+	}
+
+	if p.Pregnant && p.Energy >= p.CriticalHungerThreshold {
+		if nest, ok := p.Nest(); ok {
+			if p.Sheltered {
+				return Intent{Kind: Rest}
+			}
+			return Intent{Kind: Return, Target: nest}
+		}
+	}
+
+	if p.Energy >= p.CriticalHungerThreshold {
+		if intruders := p.Intruders(); len(intruders) > 0 {
+			return Intent{Kind: Challenge, Target: intruders[0]}
+		}
+	}
+
+	if p.Energy >= p.CriticalHungerThreshold && p.Cooldown == 0 && p.Stage != Juvenile && !p.Pregnant {
+		if mates := p.Mates(); len(mates) > 0 {
+			return Intent{Kind: Court, Target: mates[0]}
+		}
+		return roam(p, rng)
+	}
+
+	if p.Group.Kind == config.GroupPack {
+		if intent, ok := hunt(p); ok {
+			return intent
+		}
+	}
+	if food := p.Food(); len(food) > 0 {
+		return Intent{Kind: Forage, Target: food[0]}
+	}
+	return roam(p, rng)
+}
+
+// roam leaves the territories of others, heads home when the animal has
+// strayed beyond its home range, and otherwise moves with the herd, or
+// wanders for animals without one.
+func roam(p *Perception, rng *rand.Rand) Intent {
+	if intent, ok := disperse(p); ok {
+		return intent
+	}
+	if nest, ok := p.Nest(); ok && p.Home.Range > 0 && nest.Distance > p.Home.Range {
+		return Intent{Kind: Return, Target: nest}
+	}
+	if p.Group.Kind == config.GroupHerd {
+		if dx, dy, ok := herd(p, rng); ok {
+			return Intent{Kind: Walk, DX: dx, DY: dy}
+		}
+	}
+	return Intent{Kind: Wander}
+}
+
+// disperse steers a grown territorial animal out of the territories of
+// others and, until it holds one of its own, away from where it was born.
+func disperse(p *Perception) (Intent, bool) {
+	if p.Territory.Radius <= 0 || p.Stage == Juvenile {
+		return Intent{}, false
+	}
+	var dx, dy float64
+	for _, t := range p.Trespassing() {
+		if t.Distance > 0 {
+			dx -= t.DX / t.Distance
+			dy -= t.DY / t.Distance
+		}
+	}
+	if _, ok := p.Claim(); !ok {
+		if birth, ok := p.Birthplace(); ok && birth.Distance > 0 && birth.Distance < p.Territory.Dispersal {
+			dx -= birth.DX / birth.Distance
+			dy -= birth.DY / birth.Distance
+		}
+	}
+	if dx == 0 && dy == 0 {
+		return Intent{}, false
+	}
+	return Intent{Kind: Walk, DX: dx, DY: dy}, true
+}
+
+// wanderer is a baseline without goals: it roams at random and only eats
+// or mates with what it happens to come within reach of.
+type wanderer struct{}
+
+func (wanderer) Decide(p *Perception, rng *rand.Rand) Intent {
+	if food := p.Food(); len(food) > 0 && food[0].Distance < p.InteractionDistance && p.Energy < p.MaxEnergy {
+		return Intent{Kind: Forage, Target: food[0]}
+	}
+	if p.Cooldown == 0 && p.Stage != Juvenile && !p.Pregnant {
+		if mates := p.Mates(); len(mates) > 0 && mates[0].Distance < p.InteractionDistance {
+			return Intent{Kind: Court, Target: mates[0]}
+		}
+	}
+	return Intent{Kind: Wander}
+}
+
+func init() {
+	RegisterBrain(DefaultBrain, func() Brain { return forager{} })
+	RegisterBrain("wanderer", func() Brain { return wanderer{} })
+}
@@ -0,0 +1,129 @@
+package entities
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+type Sex string
+
+const (
+	Male   Sex = "male"
+	Female Sex = "female"
+)
+
+func randomSex(rng *rand.Rand, maleRatio float64) Sex {
+	if rng.Float64() < maleRatio {
+		return Male
+	}
+	return Female
+}
+
+// Pregnancy is a litter a female carries until Remaining reaches zero.
+// The father's genome is kept so that the young inherit from him even if
+// he dies before they are born. Provision is the energy set aside for the
+// young so far; the mother adds Rate to it every tick.
+type Pregnancy struct {
+	Litter    int     `json:"litter"`
+	Remaining int     `json:"remaining"`
+	Father    Genome  `json:"father"`
+	Provision float64 `json:"provision"`
+	Rate      float64 `json:"rate"`
+}
+
+// Bearer is implemented by animals that come in two sexes, the females
+// carrying their young for a while before giving birth.
+type Bearer interface {
+	GetSex() Sex
+	Pregnant() bool
+	// Carried is the energy set aside for the litter the animal carries.
+	Carried() float64
+	Conceive(p Pregnancy)
+	// Deliver ends a pregnancy that has come to term.
+	Deliver() (Pregnancy, bool)
+}
+
+// Provisioned is implemented by animals that are born with the energy
+// their parents gave them rather than the species default.
+type Provisioned interface {
+	SetEnergy(energy float64)
+}
+
+func (a *Animal) GetSex() Sex    { return a.Sex }
+func (a *Animal) Pregnant() bool { return a.Pregnancy != nil }
+
+// CanMateWith reports whether partner is of the animal's species and the
+// other sex.
+func (a *Animal) CanMateWith(partner Entity) bool {
+	mate, ok := partner.(Bearer)
+	return ok && partner.GetSpecies() == a.Species && mate.GetSex() != a.Sex
+}
+
+func (a *Animal) Carried() float64 {
+	if a.Pregnancy == nil {
+		return 0
+	}
+	return a.Pregnancy.Provision
+}
+
+func (a *Animal) Conceive(p Pregnancy) { a.Pregnancy = &p }
+
+func (a *Animal) SetEnergy(energy float64) { a.Energy = energy }
+
+func (a *Animal) Deliver() (Pregnancy, bool) {
+	if a.Pregnancy == nil || a.Pregnancy.Remaining > 0 {
+		return Pregnancy{}, false
+	}
+	p := *a.Pregnancy
+	a.Pregnancy = nil
+	return p, true
+}
+
+// gestate brings a pregnancy one tick closer to term.
+func (a *Animal) gestate() {
+	if a.Pregnancy != nil && a.Pregnancy.Remaining > 0 {
+		a.Pregnancy.Remaining--
+	}
+}
+
+// gestationCost is the energy a pregnant female sets aside for her litter
+// this tick.
+func (a *Animal) gestationCost() float64 {
+	if a.Pregnancy == nil || a.Pregnancy.Remaining == 0 {
+		return 0
+	}
+	return a.Pregnancy.Rate
+}
+
+// chooseMates orders mates by the animal's mate choice, the preferred
+// first; ties keep their order, which is closest first.
+func (a *Animal) chooseMates(mates []Sighting) {
+	var score func(Entity) float64
+	switch choice := a.Reproduction.MateChoice; choice {
+	case "", config.MateNearest:
+		return
+	case config.MateEnergy:
+		score = func(e Entity) float64 { return e.GetEnergy() }
+	default:
+		trait := -1
+		for i, name := range TraitNames {
+			if name == choice {
+				trait = i
+			}
+		}
+		if trait < 0 {
+			return
+		}
+		score = func(e Entity) float64 {
+			if heritable, ok := e.(Heritable); ok {
+				return heritable.GetGenome().Traits()[trait]
+			}
+			return 0
+		}
+	}
+	sort.SliceStable(mates, func(i, j int) bool {
+		return score(mates[i].Entity) > score(mates[j].Entity)
+	})
+}
@@ -0,0 +1,83 @@
+package entities
+
+import "github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+
+type Health int
+
+const (
+	Susceptible Health = iota
+	Infected
+	Recovered
+)
+
+// HealthStates lists every state in order.
+var HealthStates = []Health{Susceptible, Infected, Recovered}
+
+func (h Health) String() string {
+	switch h {
+	case Infected:
+		return "infected"
+	case Recovered:
+		return "recovered"
+	default:
+		return "susceptible"
+	}
+}
+
+// Infectable is implemented by entities that can catch a disease.
+type Infectable interface {
+	GetHealth() Health
+	// Infect makes a susceptible entity infected and reports whether it
+	// was.
+	Infect() bool
+	// Contagion is the disease of the entity's species.
+	Contagion() (transmission, radius, fromPrey float64)
+}
+
+func (a *Animal) GetHealth() Health { return a.Health }
+
+func (a *Animal) Infect() bool {
+	if a.Health != Susceptible || a.Disease.Duration <= 0 {
+		return false
+	}
+	a.Health = Infected
+	a.HealthTimer = a.Disease.Duration
+	return true
+}
+
+func (a *Animal) Contagion() (transmission, radius, fromPrey float64) {
+	return a.Disease.Transmission, a.Disease.Radius, a.Disease.FromPrey
+}
+
+// sicknessLoss is the factor by which the animal's health scales its
+// energy loss.
+func (a *Animal) sicknessLoss() float64 {
+	if a.Health == Infected {
+		return a.Disease.EnergyLoss
+	}
+	return 1
+}
+
+// progressDisease advances the animal's infection or immunity by a tick
+// and reports whether the disease killed it.
+func (a *Animal) progressDisease(world WorldInterface) bool {
+	switch a.Health {
+	case Infected:
+		if a.Disease.Mortality > 0 && world.Random().Float64() < a.Disease.Mortality {
+			a.Kill(interfaces.Disease)
+			return true
+		}
+		if a.HealthTimer--; a.HealthTimer <= 0 {
+			a.Health = Recovered
+			a.HealthTimer = a.Disease.Immunity
+		}
+	case Recovered:
+		// A zero immunity lasts for good.
+		if a.HealthTimer > 0 {
+			if a.HealthTimer--; a.HealthTimer == 0 {
+				a.Health = Susceptible
+			}
+		}
+	}
+	return false
+}
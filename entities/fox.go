@@ -1,27 +1,99 @@
 package entities
 
 import (
-	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"image/color"
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
 )
 
 type Fox struct {
 	Animal
 } 
 
-func NewFox(x, y float64) *Fox {
+func NewFox(x, y float64, cfg config.Species, rng *rand.Rand) *Fox {
 	return &Fox{
-		Animal: Animal{
-			Pos: geom.Point{X: x, Y: y},
-			Energy: 200.0,
-			MaxEnergy: 300.0,
-			EnergyLoss: 3.0,
-			CriticalHungerThreshold: 170.0,
-			ReproduceCooldown: 0,
-			SearchRadius: 60.0,
-			Species: "fox",
-			FoodType: "rabbit",
-			MovementSpeed: 2.0,
-			Alive: true,
-		},
+		Animal: newAnimal("fox", x, y, cfg, rng),
 	}
-}
\ No newline at end of file
+}
+
+func init() {
+	RegisterSpecies(SpeciesInfo{
+		Name: "fox",
+		Label: "Foxes",
+		Animal: true,
+		New: func(x, y float64, cfg *config.Config, rng *rand.Rand) Entity {
+			return NewFox(x, y, cfg.Species["fox"], rng)
+		},
+		Eaten: EatAnimal,
+		Color: color.RGBA{255, 100, 100, 255},
+	}, &config.Species{
+		Energy: 200.0,
+		MaxEnergy: 300.0,
+		EnergyLoss: 3.0,
+		CriticalHungerThreshold: 170.0,
+		SearchRadius: 60.0,
+		MovementSpeed: 2.0,
+		Diet: []string{"rabbit"},
+		InteractionDistance: 5.0,
+		ReproduceCooldown: 40,
+		MatingEnergyCost: 10.0,
+		Life: config.Life{
+			MaturityAge: 100,
+			SeniorAge: 1000,
+			Juvenile: config.StageTraits{Speed: 0.8, EnergyLoss: 0.8},
+			Senior: config.StageTraits{Speed: 0.8, EnergyLoss: 1.1},
+			SeniorFertility: 0.5,
+			MortalityBase: 0.0001,
+			MortalityRate: 0.005,
+		},
+		Reproduction: config.Reproduction{
+			MaleRatio: 0.5,
+			Gestation: 30,
+			LitterMin: 1,
+			LitterMax: 3,
+			LitterWeights: []float64{1, 2, 1},
+			OffspringEnergy: 80.0,
+			PaternalShare: 0.25,
+			MateChoice: config.MateNearest,
+		},
+		Night: config.Night{
+			SearchRadius: 1.5,
+			Speed: 1.0,
+		},
+		Disease: config.Disease{
+			Transmission: 0.05,
+			Radius: 5.0,
+			Duration: 60,
+			EnergyLoss: 1.3,
+			Mortality: 0.003,
+			Immunity: 300,
+			FromPrey: 0.3,
+		},
+		Group: config.Group{
+			Kind: config.GroupPack,
+			Radius: 30.0,
+			Spacing: 8.0,
+		},
+		Home: config.Home{
+			Structure: "den",
+			Radius: 4.0,
+			Range: 80.0,
+			Rest: 0.7,
+			Hide: false,
+		},
+		Territory: config.Territory{
+			Radius: 40.0,
+			Dispersal: 60.0,
+			MarkLife: 150,
+			FightCost: 15.0,
+		},
+		Brain: DefaultBrain,
+		Genetics: config.Genetics{
+			MutationRate: 0.1,
+			MutationSigma: 0.05,
+			SpeedCost: 1.0,
+			RadiusCost: 0.5,
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+// Genome holds the heritable traits of an animal. EnergyLoss is the base
+// metabolic cost; the animal's actual loss per tick also pays for its
+// speed and search radius, see Express.
+type Genome struct {
+	MovementSpeed           float64 `json:"movementSpeed"`
+	SearchRadius            float64 `json:"searchRadius"`
+	EnergyLoss              float64 `json:"energyLoss"`
+	CriticalHungerThreshold float64 `json:"criticalHungerThreshold"`
+}
+
+// Heritable is implemented by entities that pass a genome to offspring.
+type Heritable interface {
+	GetGenome() Genome
+	Express(g Genome, cfg config.Species)
+}
+
+// DefaultGenome is the genome of an animal of the species' default stats.
+func DefaultGenome(cfg config.Species) Genome {
+	return Genome{
+		MovementSpeed:           cfg.MovementSpeed,
+		SearchRadius:            cfg.SearchRadius,
+		EnergyLoss:              cfg.EnergyLoss,
+		CriticalHungerThreshold: cfg.CriticalHungerThreshold,
+	}
+}
+
+// Traits lists the genome's values in a fixed order, matching TraitNames.
+func (g Genome) Traits() []float64 {
+	return []float64{g.MovementSpeed, g.SearchRadius, g.EnergyLoss, g.CriticalHungerThreshold}
+}
+
+var TraitNames = []string{"movementSpeed", "searchRadius", "energyLoss", "criticalHungerThreshold"}
+
+// GenomeFromTraits is the inverse of Genome.Traits.
+func GenomeFromTraits(t []float64) Genome {
+	return Genome{
+		MovementSpeed:           t[0],
+		SearchRadius:            t[1],
+		EnergyLoss:              t[2],
+		CriticalHungerThreshold: t[3],
+	}
+}
+
+// Crossover builds a child genome taking each trait from a random parent
+// and then mutating it with probability MutationRate by a Gaussian factor
+// of relative standard deviation MutationSigma. Traits never drop below a
+// hundredth of the species default.
+func Crossover(a, b Genome, cfg config.Species, rng *rand.Rand) Genome {
+	ta, tb := a.Traits(), b.Traits()
+	floor := DefaultGenome(cfg).Traits()
+
+	child := make([]float64, len(ta))
+	for i := range child {
+		child[i] = ta[i]
+		if rng.IntN(2) == 1 {
+			child[i] = tb[i]
+		}
+
+		if rng.Float64() < cfg.Genetics.MutationRate {
+			child[i] *= 1 + rng.NormFloat64()*cfg.Genetics.MutationSigma
+		}
+		child[i] = math.Max(child[i], floor[i]*0.01)
+	}
+
+	return GenomeFromTraits(child)
+}
+
+// MetabolicCost is the energy an animal with genome g loses per tick.
+// Being faster or seeing further than the species default costs energy
+// according to the SpeedCost and RadiusCost exponents.
+func MetabolicCost(g Genome, cfg config.Species) float64 {
+	cost := g.EnergyLoss
+	if cfg.MovementSpeed > 0 {
+		cost *= math.Pow(g.MovementSpeed/cfg.MovementSpeed, cfg.Genetics.SpeedCost)
+	}
+	if cfg.SearchRadius > 0 {
+		cost *= math.Pow(g.SearchRadius/cfg.SearchRadius, cfg.Genetics.RadiusCost)
+	}
+	return cost
+}
@@ -1,43 +1,60 @@
 package entities
 
 import (
-	"math/rand"
+	"image/color"
+	"math/rand/v2"
 
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
 	"github.com/j-bisew/foxes-rabbits-simulation/geom"
 	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
 )
 
 type Grass struct {
+	ID uint64
 	Pos geom.Point
 	Amount, MaxAmount, GrowthRate float64
 	Alive bool
+	DeathCause interfaces.Cause
 }
 
-func NewGrass(x,y float64) *Grass {
+func NewGrass(x,y float64, cfg config.Grass, rng *rand.Rand) *Grass {
 	return &Grass{
 		Pos: geom.Point{X: x, Y: y},
 		Amount: 0.0,
-		MaxAmount: 50.0 + rand.Float64()*50.0,
-		GrowthRate: 0.5 + rand.Float64(),
+		MaxAmount: cfg.MaxAmountMin + rng.Float64()*(cfg.MaxAmountMax-cfg.MaxAmountMin),
+		GrowthRate: cfg.GrowthRateMin + rng.Float64()*(cfg.GrowthRateMax-cfg.GrowthRateMin),
 		Alive: true,
 	}
 }
 
+func (g *Grass) GetID() uint64           { return g.ID }
+func (g *Grass) SetID(id uint64)         { g.ID = id }
 func (g *Grass) GetPosition() geom.Point { return g.Pos }
 func (g *Grass) GetSpecies() string      { return "grass" }
-func (g *Grass) GetFoodType() string     { return "" }
+func (g *Grass) GetDiet() []string       { return nil }
 func (g *Grass) IsAlive() bool           { return g.Alive }
 func (g *Grass) GetEnergy() float64      { return g.Amount }
 func (g *Grass) UpdateEnergy(amount float64)  {
 	g.Amount += amount
 }
-func (g *Grass) Kill() { g.Alive = false }
+func (g *Grass) GetDeathCause() interfaces.Cause { return g.DeathCause }
+func (g *Grass) Kill(cause interfaces.Cause) {
+	if g.Alive {
+		g.Alive = false
+		g.DeathCause = cause
+	}
+}
 
-func (g *Grass) Update(world interfaces.WorldInterface) {
-	g.Amount += g.GrowthRate
+// Grass does not move or choose anything; it only grows.
+func (g *Grass) Decide(world interfaces.WorldInterface) interfaces.Action {
+	return interfaces.Action{}
+}
+
+func (g *Grass) Act(world interfaces.WorldInterface, action interfaces.Action) {
+	g.Amount += g.GrowthRate * world.Climate().GrassGrowth
 	if g.Amount > g.MaxAmount { g.Amount = g.MaxAmount }
 
-	if g.Amount <= 0 { g.Alive = false }
+	if g.Amount <= 0 { g.Kill(interfaces.Grazed) }
 }
 
 func (g *Grass) Consume(wantedEnergy float64) float64 {
@@ -45,8 +62,37 @@ func (g *Grass) Consume(wantedEnergy float64) float64 {
 
 	g.Amount -= wantedEnergy
 	if g.Amount <= 0.0 {
-		g.Kill()
+		g.Kill(interfaces.Grazed)
 	}
 
 	return wantedEnergy
+}
+
+// eatGrass takes a random bite, limited by what is left on the patch.
+func eatGrass(food Entity, cfg *config.Config, rng *rand.Rand) float64 {
+	grass := food.(*Grass)
+	wantedAmount := cfg.Grass.BiteMin + rng.Float64()*(cfg.Grass.BiteMax-cfg.Grass.BiteMin)
+	if grass.GetEnergy() < wantedAmount {
+		return grass.Consume(grass.GetEnergy())
+	}
+	return grass.Consume(wantedAmount)
+}
+
+func init() {
+	RegisterSpecies(SpeciesInfo{
+		Name: "grass",
+		Label: "Grass",
+		New: func(x, y float64, cfg *config.Config, rng *rand.Rand) Entity {
+			return NewGrass(x, y, cfg.Grass, rng)
+		},
+		Eaten: eatGrass,
+		Color: color.RGBA{0, 150, 0, 255},
+		Shade: func(e Entity) color.RGBA {
+			intensity := uint8(e.GetEnergy() * 2.5)
+			if intensity > 255 {
+				intensity = 255
+			}
+			return color.RGBA{0, intensity, 0, 255}
+		},
+	}, nil)
 }
\ No newline at end of file
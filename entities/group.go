@@ -0,0 +1,153 @@
+package entities
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/quadtree"
+)
+
+// Mover is implemented by entities that report their last step.
+type Mover interface {
+	GetHeading() geom.Point
+}
+
+// Groupmates lists the members of the animal's species within its group
+// radius, in ID order.
+func (p *Perception) Groupmates() []Sighting {
+	if p.sawGroup {
+		return p.group
+	}
+	p.sawGroup = true
+	if p.Group.Radius <= 0 {
+		return nil
+	}
+
+	a := p.animal
+	nearby := p.world.FindNearbyEntities(p.Position, p.Group.Radius, a.Species)
+	sort.Slice(nearby, func(i, j int) bool { return nearby[i].GetID() < nearby[j].GetID() })
+	for _, entity := range nearby {
+		if entity.GetID() == a.ID {
+			continue
+		}
+		s := p.sighting(entity, entity.GetPosition())
+		if mover, ok := entity.(Mover); ok {
+			s.Heading = mover.GetHeading()
+		}
+		p.group = append(p.group, s)
+	}
+	return p.group
+}
+
+// Pack is the hunt an animal's pack has agreed on.
+type Pack struct {
+	Target Sighting
+	// Rank is the animal's place among the Size members in ID order.
+	Rank, Size int
+}
+
+// Pack finds the prey the animal's pack hunts: the food entity closest to
+// the leader, the member with the lowest ID. Every member works this out
+// alone from what it sees, so members that see the same pack agree.
+func (p *Perception) Pack() (Pack, bool) {
+	mates := p.Groupmates()
+	if len(mates) == 0 {
+		return Pack{}, false
+	}
+
+	a := p.animal
+	leader := p.Position
+	rank := 0
+	for _, mate := range mates {
+		if mate.Entity.GetID() < a.ID {
+			rank++
+		}
+	}
+	if rank > 0 {
+		leader = mates[0].Position
+	}
+
+	edible := quadtree.And(quadtree.Alive, quadtree.EnergyAbove(0), visible)
+	var best Entity
+	bestDistance := math.Inf(1)
+	for _, food := range a.Diet {
+		for _, entity := range p.world.FindNearest(leader, p.SearchRadius, 1, food, edible) {
+			dx, dy := p.world.Offset(leader, entity.GetPosition())
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d < bestDistance || d == bestDistance && entity.GetID() < best.GetID() {
+				best, bestDistance = entity, d
+			}
+		}
+	}
+	if best == nil {
+		return Pack{}, false
+	}
+	return Pack{Target: p.sighting(best, best.GetPosition()), Rank: rank, Size: len(mates) + 1}, true
+}
+
+// herd steers by the boids rules: towards the groupmates, away from those
+// too close and along their mean heading, with a little noise so that a
+// herd at rest still drifts.
+func herd(p *Perception, rng *rand.Rand) (dx, dy float64, ok bool) {
+	mates := p.Groupmates()
+	if len(mates) == 0 {
+		return 0, 0, false
+	}
+	g := p.Group
+
+	var cx, cy, sx, sy, hx, hy float64
+	for _, m := range mates {
+		cx += m.DX
+		cy += m.DY
+		if m.Distance > 0 && m.Distance < g.Spacing {
+			push := 1 - m.Distance/g.Spacing
+			sx -= m.DX / m.Distance * push
+			sy -= m.DY / m.Distance * push
+		}
+		hx += m.Heading.X
+		hy += m.Heading.Y
+	}
+
+	ux, uy := unit(cx, cy)
+	dx += g.Cohesion * ux
+	dy += g.Cohesion * uy
+	dx += g.Separation * sx
+	dy += g.Separation * sy
+	ux, uy = unit(hx, hy)
+	dx += g.Alignment * ux
+	dy += g.Alignment * uy
+
+	angle := rng.Float64() * 2 * math.Pi
+	dx += 0.3 * math.Cos(angle)
+	dy += 0.3 * math.Sin(angle)
+	return dx, dy, true
+}
+
+// hunt has a pack member close in on the pack's target: it heads for its
+// own point on a ring of the group spacing around the prey, spread out by
+// rank, and goes for the prey once it is that close.
+func hunt(p *Perception) (Intent, bool) {
+	pack, ok := p.Pack()
+	if !ok {
+		return Intent{}, false
+	}
+	if pack.Target.Distance <= p.Group.Spacing {
+		return Intent{Kind: Forage, Target: pack.Target}, true
+	}
+	angle := 2 * math.Pi * float64(pack.Rank) / float64(pack.Size)
+	return Intent{
+		Kind: Walk,
+		DX:   pack.Target.DX + p.Group.Spacing*math.Cos(angle),
+		DY:   pack.Target.DY + p.Group.Spacing*math.Sin(angle),
+	}, true
+}
+
+func unit(x, y float64) (float64, float64) {
+	n := math.Sqrt(x*x + y*y)
+	if n == 0 {
+		return 0, 0
+	}
+	return x / n, y / n
+}
@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"math"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+type LifeStage int
+
+const (
+	Juvenile LifeStage = iota
+	Adult
+	Senior
+)
+
+// LifeStages lists every stage in order.
+var LifeStages = []LifeStage{Juvenile, Adult, Senior}
+
+func (s LifeStage) String() string {
+	switch s {
+	case Juvenile:
+		return "juvenile"
+	case Senior:
+		return "senior"
+	default:
+		return "adult"
+	}
+}
+
+// Ageing is implemented by entities that grow older.
+type Ageing interface {
+	GetAge() int
+	SetAge(age int)
+	Stage() LifeStage
+}
+
+func (a *Animal) GetAge() int      { return a.Age }
+func (a *Animal) SetAge(age int)   { a.Age = age }
+func (a *Animal) Stage() LifeStage { return stageAt(a.Age, a.Life) }
+
+func stageAt(age int, life config.Life) LifeStage {
+	switch {
+	case age < life.MaturityAge:
+		return Juvenile
+	case life.SeniorAge > 0 && age >= life.SeniorAge:
+		return Senior
+	default:
+		return Adult
+	}
+}
+
+// stageTraits returns the speed and energy loss factors of the animal's
+// current stage.
+func (a *Animal) stageTraits() config.StageTraits {
+	switch a.Stage() {
+	case Juvenile:
+		return a.Life.Juvenile
+	case Senior:
+		return a.Life.Senior
+	default:
+		return config.StageTraits{Speed: 1, EnergyLoss: 1}
+	}
+}
+
+// metabolism is the energy a healthy animal burns in a tick at its current
+// age, where it is and in the current season.
+func (a *Animal) metabolism(world WorldInterface) float64 {
+	return a.EnergyLoss * a.stageTraits().EnergyLoss * a.restLoss() * world.Climate().EnergyLoss
+}
+
+// upkeep is the energy the animal spends in a tick: its metabolism, raised
+// by sickness, and what a pregnant female sets aside for her litter.
+func (a *Animal) upkeep(world WorldInterface) float64 {
+	return a.metabolism(world)*a.sicknessLoss() + a.gestationCost()
+}
+
+// Fertility is the chance that a mating of this animal bears offspring.
+func (a *Animal) Fertility() float64 {
+	switch a.Stage() {
+	case Juvenile:
+		return 0
+	case Senior:
+		return a.Life.SeniorFertility
+	default:
+		return 1
+	}
+}
+
+// mortality is the chance of dying of old age during this tick.
+func (a *Animal) mortality() float64 {
+	if a.Life.MortalityBase <= 0 {
+		return 0
+	}
+	return a.Life.MortalityBase * math.Exp(a.Life.MortalityRate*float64(a.Age))
+}
@@ -0,0 +1,190 @@
+package entities
+
+import (
+	"math"
+	"sort"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+	"github.com/j-bisew/foxes-rabbits-simulation/quadtree"
+)
+
+// sightingLimit caps how many food entities per diet species and how many
+// mates an animal notices; an animal choosing its mates looks over
+// matePool candidates before keeping the best of them.
+const (
+	sightingLimit = 3
+	matePool      = 8
+)
+
+// Sighting is something an animal perceives around it.
+type Sighting struct {
+	// Entity is nil for a spot on the grass field or the animal's home.
+	Entity   Entity
+	Position geom.Point
+	// DX, DY is the offset from the animal, Distance its length.
+	DX, DY   float64
+	Distance float64
+	// Heading is a groupmate's last step.
+	Heading geom.Point
+}
+
+// Perception is what a Brain knows when it decides. The animal's own
+// state is given as it will be after this tick's upkeep. Sightings are
+// looked up on first use, so a brain only pays for the senses it uses.
+type Perception struct {
+	Position                geom.Point
+	Energy, MaxEnergy       float64
+	CriticalHungerThreshold float64
+	// Cooldown is the number of ticks until the animal may mate again.
+	Cooldown            int
+	Age                 int
+	Stage               LifeStage
+	Sex                 Sex
+	Pregnant            bool
+	SearchRadius        float64
+	DetectionRadius     float64
+	InteractionDistance float64
+	Group               config.Group
+	Home                config.Home
+	Territory           config.Territory
+	// Sheltered is whether the animal is inside its home.
+	Sheltered bool
+	// Routed is whether the animal lost a fight as an intruder and has
+	// yet to leave the territories of others.
+	Routed bool
+
+	animal *Animal
+	world  WorldInterface
+
+	food, mates, threats, group             []Sighting
+	sawFood, sawMates, sawThreats, sawGroup bool
+}
+
+func (a *Animal) perceive(world WorldInterface) *Perception {
+	cooldown := a.ReproduceCooldown
+	if cooldown > 0 {
+		cooldown--
+	}
+	searchRadius := a.SearchRadius
+	if world.IsNight() {
+		searchRadius *= a.Night.SearchRadius
+	}
+	return &Perception{
+		Position:                a.Pos,
+		Energy:                  a.Energy - a.upkeep(world),
+		MaxEnergy:               a.MaxEnergy,
+		CriticalHungerThreshold: a.CriticalHungerThreshold,
+		Cooldown:                cooldown,
+		Age:                     a.Age,
+		Stage:                   a.Stage(),
+		Sex:                     a.Sex,
+		Pregnant:                a.Pregnancy != nil,
+		SearchRadius:            searchRadius,
+		DetectionRadius:         a.DetectionRadius,
+		InteractionDistance:     a.InteractionDistance,
+		Group:                   a.Group,
+		Home:                    a.Home,
+		Territory:               a.Territory,
+		Sheltered:               a.Sheltered,
+		Routed:                  a.Routed,
+		animal:                  a,
+		world:                   world,
+	}
+}
+
+func (p *Perception) sighting(entity Entity, pos geom.Point) Sighting {
+	dx, dy := p.world.Offset(p.Position, pos)
+	return Sighting{Entity: entity, Position: pos, DX: dx, DY: dy, Distance: math.Sqrt(dx*dx + dy*dy)}
+}
+
+// Food lists food within the search radius, closest first: the nearest
+// grazable spot and the nearest few entities of every species in the diet.
+func (p *Perception) Food() []Sighting {
+	if p.sawFood {
+		return p.food
+	}
+	p.sawFood = true
+
+	edible := quadtree.And(quadtree.Alive, quadtree.EnergyAbove(0), visible)
+	for _, food := range p.animal.Diet {
+		if spot, ok := p.world.FindPasture(p.Position, p.SearchRadius, food); ok {
+			p.food = append(p.food, p.sighting(nil, spot))
+		}
+		for _, entity := range p.world.FindNearest(p.Position, p.SearchRadius, sightingLimit, food, edible) {
+			p.food = append(p.food, p.sighting(entity, entity.GetPosition()))
+		}
+	}
+
+	// At equal distance grazing spots come first, then entities by ID.
+	sort.SliceStable(p.food, func(i, j int) bool {
+		a, b := p.food[i], p.food[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.Entity == nil || b.Entity == nil {
+			return a.Entity == nil && b.Entity != nil
+		}
+		return a.Entity.GetID() < b.Entity.GetID()
+	})
+	return p.food
+}
+
+// Nest is the animal's home; ok is false while it has none.
+func (p *Perception) Nest() (Sighting, bool) {
+	nest, ok := p.animal.GetNest()
+	if !ok {
+		return Sighting{}, false
+	}
+	return p.sighting(nil, nest), true
+}
+
+// Mates lists a few members of the animal's species within the search
+// radius that it can mate with and that are ready to, the preferred first
+// by the species' mate choice and otherwise the closest.
+func (p *Perception) Mates() []Sighting {
+	if p.sawMates {
+		return p.mates
+	}
+	p.sawMates = true
+
+	a := p.animal
+	ready := quadtree.And(quadtree.Alive, quadtree.EnergyAbove(0), func(entity Entity) bool {
+		mate, ok := entity.(interfaces.Breeder)
+		return ok && entity.GetID() != a.ID && mate.CanReproduce() && a.CanMateWith(entity)
+	})
+	pool := sightingLimit
+	if choice := a.Reproduction.MateChoice; choice != "" && choice != config.MateNearest {
+		pool = matePool
+	}
+	for _, entity := range p.world.FindNearest(p.Position, p.SearchRadius, pool, a.Species, ready) {
+		p.mates = append(p.mates, p.sighting(entity, entity.GetPosition()))
+	}
+	a.chooseMates(p.mates)
+	if len(p.mates) > sightingLimit {
+		p.mates = p.mates[:sightingLimit]
+	}
+	return p.mates
+}
+
+// Threats lists every predator within the detection radius, in ID order
+// so that anything summed over them does not depend on the spatial index.
+func (p *Perception) Threats() []Sighting {
+	if p.sawThreats {
+		return p.threats
+	}
+	p.sawThreats = true
+	if p.DetectionRadius <= 0 {
+		return nil
+	}
+
+	for _, predator := range p.world.PredatorsOf(p.animal.Species) {
+		threats := p.world.FindNearbyEntities(p.Position, p.DetectionRadius, predator)
+		sort.Slice(threats, func(i, j int) bool { return threats[i].GetID() < threats[j].GetID() })
+		for _, threat := range threats {
+			p.threats = append(p.threats, p.sighting(threat, threat.GetPosition()))
+		}
+	}
+	return p.threats
+}
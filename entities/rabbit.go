@@ -1,26 +1,105 @@
 package entities
 
 import (
-	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"image/color"
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
 )
 type Rabbit struct {
 	Animal
 } 
 
-func NewRabbit(x, y float64) *Rabbit {
+func NewRabbit(x, y float64, cfg config.Species, rng *rand.Rand) *Rabbit {
 	return &Rabbit{
-		Animal: Animal{
-			Pos: geom.Point{X: x, Y: y},
-			Energy: 125.0,
-			MaxEnergy: 200.0,
+		Animal: newAnimal("rabbit", x, y, cfg, rng),
+	}
+}
+
+func init() {
+	RegisterSpecies(SpeciesInfo{
+		Name: "rabbit",
+		Label: "Rabbits",
+		Animal: true,
+		New: func(x, y float64, cfg *config.Config, rng *rand.Rand) Entity {
+			return NewRabbit(x, y, cfg.Species["rabbit"], rng)
+		},
+		Eaten: EatAnimal,
+		Color: color.RGBA{150, 150, 150, 255},
+	}, &config.Species{
+		Energy: 125.0,
+		MaxEnergy: 200.0,
+		EnergyLoss: 1.5,
+		CriticalHungerThreshold: 100.0,
+		SearchRadius: 40.0,
+		MovementSpeed: 3.5,
+		Diet: []string{"grass"},
+		InteractionDistance: 5.0,
+		ReproduceCooldown: 40,
+		MatingEnergyCost: 10.0,
+		Life: config.Life{
+			MaturityAge: 60,
+			SeniorAge: 600,
+			Juvenile: config.StageTraits{Speed: 0.8, EnergyLoss: 0.8},
+			Senior: config.StageTraits{Speed: 0.8, EnergyLoss: 1.1},
+			SeniorFertility: 0.5,
+			MortalityBase: 0.0001,
+			MortalityRate: 0.008,
+		},
+		Reproduction: config.Reproduction{
+			MaleRatio: 0.5,
+			Gestation: 20,
+			LitterMin: 1,
+			LitterMax: 4,
+			LitterWeights: []float64{2, 3, 3, 2},
+			OffspringEnergy: 40.0,
+			PaternalShare: 0.0,
+			MateChoice: config.MateNearest,
+		},
+		Night: config.Night{
+			SearchRadius: 0.8,
+			Speed: 0.9,
+		},
+		Disease: config.Disease{
+			Transmission: 0.05,
+			Radius: 5.0,
+			Duration: 60,
 			EnergyLoss: 1.5,
-			CriticalHungerThreshold: 100.0,
-			ReproduceCooldown: 0,
-			SearchRadius: 40.0,
-			Species: "rabbit",
-			FoodType: "grass",
-			MovementSpeed: 3.5,
-			Alive: true,
+			Mortality: 0.005,
+			Immunity: 300,
+			FromPrey: 0.0,
 		},
-	}
-}
\ No newline at end of file
+		Group: config.Group{
+			Kind: config.GroupHerd,
+			Radius: 20.0,
+			Spacing: 4.0,
+			Cohesion: 1.0,
+			Separation: 1.5,
+			Alignment: 0.5,
+		},
+		Home: config.Home{
+			Structure: "burrow",
+			Radius: 3.0,
+			Range: 60.0,
+			Rest: 0.5,
+			Hide: true,
+		},
+		Brain: DefaultBrain,
+		Genetics: config.Genetics{
+			MutationRate: 0.1,
+			MutationSigma: 0.05,
+			SpeedCost: 1.0,
+			RadiusCost: 0.5,
+		},
+		NutritionBase: 80.0,
+		NutritionFactor: 0.3,
+		// A rabbit only notices a fox about as far off as the fox can
+		// reach, and dodges slower than a fox runs, so that foxes still
+		// catch the rabbits they close in on.
+		Flee: config.Flee{
+			DetectionRadius: 6.0,
+			PanicSpeed: 0.55,
+			EnergyCost: 3.0,
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"image/color"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// SpeciesInfo is everything the world and the GUI need to know about a
+// species. A new species only has to register one of these.
+type SpeciesInfo struct {
+	Name  string
+	Label string
+	// Animals move and reproduce and can be placed on the setup page;
+	// plants are spawned by the world itself. Structures are static homes
+	// that are placed with the animals but are not counted as species.
+	Animal    bool
+	Structure bool
+
+	New func(x, y float64, cfg *config.Config, rng *rand.Rand) Entity
+	// Eaten is the energy-transfer rule: it applies the effect of being
+	// eaten to food and returns the energy the eater gains.
+	Eaten func(food Entity, cfg *config.Config, rng *rand.Rand) float64
+
+	// Color is used for the chart and legend; Shade, when set, varies the
+	// color of individual entities on the board.
+	Color color.RGBA
+	Shade func(e Entity) color.RGBA
+}
+
+// ColorOf returns the board color of e.
+func (s SpeciesInfo) ColorOf(e Entity) color.RGBA {
+	if s.Shade != nil {
+		return s.Shade(e)
+	}
+	return s.Color
+}
+
+var registry = map[string]SpeciesInfo{}
+
+// RegisterSpecies adds a species to the registry. Animals must also
+// provide their default parameters, which become part of config.Default.
+func RegisterSpecies(info SpeciesInfo, defaults *config.Species) {
+	if _, exists := registry[info.Name]; exists {
+		panic("entities: species " + info.Name + " registered twice")
+	}
+	registry[info.Name] = info
+	config.RegisterSpecies(info.Name, defaults)
+}
+
+func LookupSpecies(name string) (SpeciesInfo, bool) {
+	info, ok := registry[name]
+	return info, ok
+}
+
+// AllSpecies returns every registered animal and plant ordered by name.
+func AllSpecies() []SpeciesInfo {
+	all := make([]SpeciesInfo, 0, len(registry))
+	for _, info := range registry {
+		if !info.Structure {
+			all = append(all, info)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// AnimalSpecies returns the registered animals ordered by name.
+func AnimalSpecies() []SpeciesInfo {
+	var animals []SpeciesInfo
+	for _, info := range AllSpecies() {
+		if info.Animal {
+			animals = append(animals, info)
+		}
+	}
+	return animals
+}
+
+// StructureKinds returns the registered structures ordered by name.
+func StructureKinds() []SpeciesInfo {
+	var kinds []SpeciesInfo
+	for _, info := range registry {
+		if info.Structure {
+			kinds = append(kinds, info)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Name < kinds[j].Name })
+	return kinds
+}
+
+// EatAnimal is the energy-transfer rule shared by animals: the prey dies and
+// the eater gains the prey's base nutrition plus a share of its energy.
+func EatAnimal(food Entity, cfg *config.Config, rng *rand.Rand) float64 {
+	prey := cfg.Species[food.GetSpecies()]
+	energyGain := prey.NutritionBase + (food.GetEnergy() * prey.NutritionFactor)
+	food.Kill(interfaces.Predation)
+	return energyGain
+}
@@ -0,0 +1,113 @@
+package entities
+
+import (
+	"image/color"
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// Structure is a static home such as a burrow or a den. It never moves,
+// holds no energy and lasts for the whole run.
+type Structure struct {
+	ID   uint64
+	Kind string
+	Pos  geom.Point
+}
+
+func NewStructure(kind string, x, y float64) *Structure {
+	return &Structure{Kind: kind, Pos: geom.Point{X: x, Y: y}}
+}
+
+func (s *Structure) GetID() uint64                   { return s.ID }
+func (s *Structure) SetID(id uint64)                 { s.ID = id }
+func (s *Structure) GetPosition() geom.Point         { return s.Pos }
+func (s *Structure) GetSpecies() string              { return s.Kind }
+func (s *Structure) GetDiet() []string               { return nil }
+func (s *Structure) IsAlive() bool                   { return true }
+func (s *Structure) GetEnergy() float64              { return 0 }
+func (s *Structure) UpdateEnergy(amount float64)     {}
+func (s *Structure) Kill(cause interfaces.Cause)     {}
+func (s *Structure) GetDeathCause() interfaces.Cause { return "" }
+
+func (s *Structure) Decide(world interfaces.WorldInterface) interfaces.Action {
+	return interfaces.Action{}
+}
+
+func (s *Structure) Act(world interfaces.WorldInterface, action interfaces.Action) {}
+
+// Homing is implemented by animals that live in a structure.
+type Homing interface {
+	// GetNest returns where the animal lives; ok is false while it has no
+	// home.
+	GetNest() (nest geom.Point, ok bool)
+	// MoveIn makes nest the animal's home.
+	MoveIn(world WorldInterface, nest geom.Point)
+	// IsSheltered reports whether the animal is inside its home.
+	IsSheltered() bool
+	// Hidden reports whether predators cannot find the animal.
+	Hidden() bool
+}
+
+func (a *Animal) GetNest() (geom.Point, bool) {
+	if a.Nest == nil {
+		return geom.Point{}, false
+	}
+	return *a.Nest, true
+}
+
+func (a *Animal) MoveIn(world WorldInterface, nest geom.Point) {
+	a.Nest = &nest
+	a.settle(world)
+}
+
+func (a *Animal) IsSheltered() bool { return a.Sheltered }
+func (a *Animal) Hidden() bool      { return a.Sheltered && a.Home.Hide }
+
+// settle has a homeless animal take the nearest structure of its kind in
+// sight as its home, and notes whether it is inside its home.
+// Territorial animals only settle outside the territories of others.
+func (a *Animal) settle(world WorldInterface) {
+	if a.Nest == nil && a.Home.Structure != "" {
+		if found := world.FindNearest(a.Pos, a.SearchRadius, 1, a.Home.Structure, a.unclaimed(world)); len(found) > 0 {
+			nest := found[0].GetPosition()
+			a.Nest = &nest
+		}
+	}
+	a.Sheltered = false
+	if a.Nest != nil {
+		_, _, distance := a.DistanceTo(world, *a.Nest)
+		a.Sheltered = distance <= a.Home.Radius
+	}
+}
+
+// restLoss is the factor by which resting at home scales the animal's
+// energy loss.
+func (a *Animal) restLoss() float64 {
+	if a.Sheltered {
+		return a.Home.Rest
+	}
+	return 1
+}
+
+// visible filters out animals hiding from their predators.
+func visible(entity Entity) bool {
+	hider, ok := entity.(Homing)
+	return !ok || !hider.Hidden()
+}
+
+func init() {
+	for _, info := range []SpeciesInfo{
+		{Name: "burrow", Label: "Burrows", Color: color.RGBA{140, 90, 40, 255}},
+		{Name: "den", Label: "Dens", Color: color.RGBA{160, 40, 20, 255}},
+	} {
+		kind := info.Name
+		info.Structure = true
+		info.New = func(x, y float64, cfg *config.Config, rng *rand.Rand) Entity {
+			return NewStructure(kind, x, y)
+		}
+		RegisterSpecies(info, nil)
+	}
+}
@@ -0,0 +1,171 @@
+package entities
+
+import (
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+	"github.com/j-bisew/foxes-rabbits-simulation/quadtree"
+)
+
+// Territorial is implemented by animals that can hold a territory.
+type Territorial interface {
+	// GetTerritory returns the animal's territory; ok is false while it
+	// holds none.
+	GetTerritory() (territory interfaces.Territory, ok bool)
+	// Yield gives up the animal's territory.
+	Yield()
+	// Rout marks an intruder beaten by an owner, which then leaves the
+	// territories of others before doing anything else; IsRouted reports
+	// whether it has yet to get out.
+	Rout()
+	IsRouted() bool
+	// BornAt records where the animal was born, which it disperses from.
+	BornAt(p geom.Point)
+}
+
+func (a *Animal) GetTerritory() (interfaces.Territory, bool) {
+	if a.Claim == nil {
+		return interfaces.Territory{}, false
+	}
+	return interfaces.Territory{Owner: a, Center: *a.Claim, Radius: a.Territory.Radius}, true
+}
+
+func (a *Animal) Yield() {
+	a.Claim = nil
+	a.Marked = 0
+}
+
+func (a *Animal) Rout()          { a.Routed = true }
+func (a *Animal) IsRouted() bool { return a.Routed }
+
+func (a *Animal) BornAt(p geom.Point) { a.Birthplace = &p }
+
+// claimedByOther reports whether p lies in the territory of another member
+// of the animal's species.
+func (a *Animal) claimedByOther(world WorldInterface, p geom.Point) bool {
+	for _, t := range world.Territories(a.Species) {
+		if t.Owner.GetID() == a.ID {
+			continue
+		}
+		dx, dy := world.Offset(t.Center, p)
+		if dx*dx+dy*dy <= t.Radius*t.Radius {
+			return true
+		}
+	}
+	return false
+}
+
+// holdTerritory marks the animal's territory while it is inside, and has
+// a grown animal without one claim ground once it has dispersed. A young
+// animal gives up the home it was born into when that lies in another's
+// territory, and a routed animal is over its defeat once it is out of the
+// territories of others.
+func (a *Animal) holdTerritory(world WorldInterface) {
+	t := a.Territory
+	if t.Radius <= 0 || a.Stage() == Juvenile {
+		return
+	}
+	if a.Routed && !a.claimedByOther(world, a.Pos) {
+		a.Routed = false
+	}
+
+	if a.Claim != nil {
+		if _, _, distance := a.DistanceTo(world, *a.Claim); distance <= t.Radius {
+			a.Marked = t.MarkLife
+		} else if a.Marked--; a.Marked <= 0 {
+			a.Yield()
+		}
+		return
+	}
+
+	if a.Nest != nil && a.claimedByOther(world, *a.Nest) {
+		a.Nest = nil
+		a.Sheltered = false
+	}
+	if a.Birthplace != nil {
+		if _, _, distance := a.DistanceTo(world, *a.Birthplace); distance < t.Dispersal {
+			return
+		}
+	}
+	center := a.Pos
+	if a.Nest != nil {
+		center = *a.Nest
+	}
+	if a.claimedByOther(world, center) {
+		return
+	}
+	a.Claim = &center
+	a.Marked = t.MarkLife
+}
+
+// unclaimed filters out homes in the territory of another member of the
+// animal's species, which territorial animals do not settle in.
+func (a *Animal) unclaimed(world WorldInterface) func(Entity) bool {
+	if a.Territory.Radius <= 0 {
+		return quadtree.Alive
+	}
+	return quadtree.And(quadtree.Alive, func(e Entity) bool {
+		return !a.claimedByOther(world, e.GetPosition())
+	})
+}
+
+// Claim is the centre of the animal's territory; ok is false while it
+// holds none.
+func (p *Perception) Claim() (Sighting, bool) {
+	t, ok := p.animal.GetTerritory()
+	if !ok {
+		return Sighting{}, false
+	}
+	return p.sighting(nil, t.Center), true
+}
+
+// Birthplace is where the animal was born; ok is false for animals placed
+// on the board.
+func (p *Perception) Birthplace() (Sighting, bool) {
+	if p.animal.Birthplace == nil {
+		return Sighting{}, false
+	}
+	return p.sighting(nil, *p.animal.Birthplace), true
+}
+
+// Trespassing lists the territories of others the animal stands in, as
+// sightings of their owners at the territories' centres, by owner ID.
+func (p *Perception) Trespassing() []Sighting {
+	var trespassing []Sighting
+	for _, t := range p.world.Territories(p.animal.Species) {
+		if t.Owner.GetID() == p.animal.ID {
+			continue
+		}
+		if s := p.sighting(t.Owner, t.Center); s.Distance <= t.Radius {
+			trespassing = append(trespassing, s)
+		}
+	}
+	return trespassing
+}
+
+// Intruders lists a few grown members of the animal's sex that are within
+// both its territory and its search radius, closest first. Intruders
+// already beaten are left to get out.
+func (p *Perception) Intruders() []Sighting {
+	a := p.animal
+	t, ok := a.GetTerritory()
+	if !ok {
+		return nil
+	}
+	intruder := quadtree.And(quadtree.Alive, quadtree.EnergyAbove(0), func(entity Entity) bool {
+		bearer, ok1 := entity.(Bearer)
+		ageing, ok2 := entity.(Ageing)
+		if !ok1 || !ok2 || entity.GetID() == a.ID || bearer.GetSex() != a.Sex || ageing.Stage() == Juvenile {
+			return false
+		}
+		if other, ok := entity.(Territorial); ok && other.IsRouted() {
+			return false
+		}
+		dx, dy := p.world.Offset(t.Center, entity.GetPosition())
+		return dx*dx+dy*dy <= t.Radius*t.Radius
+	})
+	var intruders []Sighting
+	for _, entity := range p.world.FindNearest(p.Position, p.SearchRadius, sightingLimit, a.Species, intruder) {
+		intruders = append(intruders, p.sighting(entity, entity.GetPosition()))
+	}
+	return intruders
+}
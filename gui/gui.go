@@ -4,18 +4,26 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"math/rand"
+	"math"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
 	"github.com/j-bisew/foxes-rabbits-simulation/world"
 )
 
+// infectedColor marks infected animals on the board, whatever their species.
+var infectedColor = color.RGBA{230, 220, 40, 255}
+
 type GUI struct {
 	app fyne.App
 	window fyne.Window
@@ -27,16 +35,27 @@ type GUI struct {
 	gameCanvas *canvas.Raster
 	chart *canvas.Raster
 	statsLabel *widget.Label
+	eventsLabel *widget.Label
 	startBtn *widget.Button
 	stopBtn *widget.Button
+	saveBtn *widget.Button
+	openBtn *widget.Button
 	backBtn *widget.Button
+	territoryCheck *widget.Check
 
 	running bool
+	showTerritories bool
+	// territories are what drawTerritories draws, handed over from the
+	// simulation goroutine after every tick.
+	territories map[string][]interfaces.Territory
 	ticker *time.Ticker
 
-	rabbitHistory []int
-	foxHistory []int
-	grassHistory []int
+	births int
+	deaths map[interfaces.Cause]int
+	unsubscribe func()
+
+	history map[string][]int
+	historyLen int
 	maxHistory int
 }
 
@@ -51,18 +70,17 @@ func NewGUI(w *world.World) *GUI {
 		world: w,
 		running: false,
 		maxHistory: 200,
-		rabbitHistory: make([]int, 0, 200),
-		foxHistory: make([]int, 0, 200),
-		grassHistory: make([]int, 0, 200),
+		history: make(map[string][]int),
 	}
 
+	gui.watchWorld()
 	gui.setupUI()
 	gui.showSetupPage()
 	return gui
 }
 
 func (g *GUI) setupUI() {
-	g.setupPage = NewSetupPage(g.onConfigurationComplete)
+	g.setupPage = NewSetupPage(g.world.Config.Structures.Counts, g.onConfigurationComplete, g.openSnapshot)
 	g.setupSimulationPage()
 }
 
@@ -73,12 +91,19 @@ func (g *GUI) setupSimulationPage() {
 	g.chart = canvas.NewRaster(g.drawChart)
 	g.chart.Resize(fyne.NewSize(600, 200))
 
-	g.statsLabel = widget.NewLabel("Rabbits: 0, Foxes: 0, Grass: 0")
+	g.statsLabel = widget.NewLabel("")
 	g.statsLabel.TextStyle = fyne.TextStyle{Bold: true}
+	g.eventsLabel = widget.NewLabel("")
 
 	g.startBtn = widget.NewButton("Start", g.startSimulation)
 	g.stopBtn = widget.NewButton("Stop", g.stopSimulation)
+	g.saveBtn = widget.NewButton("Save", g.saveSnapshot)
+	g.openBtn = widget.NewButton("Open", g.openSnapshot)
 	g.backBtn = widget.NewButton("Back to Setup", g.showSetupPage)
+	g.territoryCheck = widget.NewCheck("Territories", func(on bool) {
+		g.showTerritories = on
+		g.gameCanvas.Refresh()
+	})
 
 	g.stopBtn.Disable()
 
@@ -86,9 +111,16 @@ func (g *GUI) setupSimulationPage() {
 		g.startBtn,
 		g.stopBtn,
 		widget.NewSeparator(),
+		g.saveBtn,
+		g.openBtn,
+		widget.NewSeparator(),
 		g.backBtn,
 		widget.NewSeparator(),
+		g.territoryCheck,
+		widget.NewSeparator(),
 		g.statsLabel,
+		widget.NewSeparator(),
+		g.eventsLabel,
 	)
 
 	gameContainer := container.NewBorder(
@@ -115,21 +147,20 @@ func (g *GUI) setupSimulationPage() {
 	)
 }
 
-func (g *GUI) showSetupPage() {
+// haltSimulation stops the ticker and gives a running tick time to finish.
+func (g *GUI) haltSimulation() {
 	if g.running {
 		g.stopSimulation()
 		time.Sleep(100 * time.Millisecond)
 	}
+}
+
+func (g *GUI) showSetupPage() {
+	g.haltSimulation()
 	
-	g.world.ClearEntities()
-	
-	g.rabbitHistory = g.rabbitHistory[:0]
-	g.foxHistory = g.foxHistory[:0]
-	g.grassHistory = g.grassHistory[:0]
+	g.world.Reset()
 	
-	totalCells := g.world.Width * g.world.Height
-	g.world.MaxGrassCount = int(float64(totalCells) * 0.70)
-	g.world.GrassSpawnRate = 0.002
+	g.clearHistory()
 	
 	g.window.SetContent(g.setupPage.GetContainer())
 }
@@ -139,52 +170,80 @@ func (g *GUI) showSimulationPage() {
 	g.updateStats()
 }
 
-func (g *GUI) onConfigurationComplete(grassPercentageBasisPoints, rabbitCount, foxCount int, spawnMode string) {
-	g.initializeWorld(grassPercentageBasisPoints, rabbitCount, foxCount, spawnMode)
+func (g *GUI) onConfigurationComplete(grassPercentageBasisPoints int, counts map[string]int, spawnMode string) {
+	g.initializeWorld(grassPercentageBasisPoints, counts, spawnMode)
 	g.showSimulationPage()
 }
 
-func (g *GUI) initializeWorld(grassPercentageBasisPoints, rabbitCount, foxCount int, spawnMode string) {
+func (g *GUI) initializeWorld(grassPercentageBasisPoints int, counts map[string]int, spawnMode string) {
 	g.world.ClearEntities()
 	
 	totalCells := g.world.Width * g.world.Height
-	g.world.MaxGrassCount = int(float64(totalCells) * 0.70)
+	g.world.MaxGrassCount = int(float64(totalCells) * g.world.Config.World.MaxGrassFraction)
 	
-	g.rabbitHistory = g.rabbitHistory[:0]
-	g.foxHistory = g.foxHistory[:0]
-	g.grassHistory = g.grassHistory[:0]
+	g.clearHistory()
 
-	maxGrass := g.world.MaxGrassCount
-	requestedGrass := int(float64(totalCells) * float64(grassPercentageBasisPoints) / 10000.0)
-	
-	grassCount := requestedGrass
-	if grassCount > maxGrass {
-		grassCount = maxGrass
-	}
+	g.world.Populate(grassPercentageBasisPoints, counts)
+}
 
-	g.world.SpawnInitialGrassRandom(grassCount)
-	
-	for i := 0; i < rabbitCount; i++ {
-		x := rand.Float64() * float64(g.world.Width)
-		y := rand.Float64() * float64(g.world.Height)
-		g.world.AddRabbit(x, y)
-	}
+func (g *GUI) saveSnapshot() {
+	g.haltSimulation()
+
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if err := g.world.Save(writer); err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	}, g.window)
+}
+
+func (g *GUI) openSnapshot() {
+	g.haltSimulation()
+
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		loaded, err := world.Load(reader)
+		if err != nil {
+			dialog.ShowError(err, g.window)
+			return
+		}
+		g.OpenWorld(loaded)
+	}, g.window)
+}
 
-	for i := 0; i < foxCount; i++ {
-		x := rand.Float64() * float64(g.world.Width)
-		y := rand.Float64() * float64(g.world.Height)
-		g.world.AddFox(x, y)
+// OpenWorld replaces the simulated world, e.g. with a loaded snapshot,
+// and shows it ready to continue. Recorders move over to the new world.
+func (g *GUI) OpenWorld(w *world.World) {
+	g.haltSimulation()
+	if w != g.world {
+		w.Recorders = append(w.Recorders, g.world.Recorders...)
 	}
+	g.world = w
+	g.watchWorld()
+	g.clearHistory()
+	g.showSimulationPage()
 }
 
 func (g *GUI) drawGame(w,h int) image.Image {
 	img := image.NewRGBA(image.Rect(0,0,w,h))
 
-	for y := 0; y<h; y++ {
-		for x := 0; x<w; x++ {
-			img.Set(x,y, color.RGBA{0,0,0,255})
-		}
-	}
+	g.drawGround(img, w, h)
 
 	for _,entity := range g.world.Entities {
 		if !entity.IsAlive() { continue }
@@ -194,27 +253,90 @@ func (g *GUI) drawGame(w,h int) image.Image {
 		y := int(pos.Y * float64(h) / float64(g.world.Height))
 
 		if x >= 0 && x < w && y >= 0 && y < h {
-			var c color.RGBA
-
-			switch entity.GetSpecies() {
-			case "rabbit":
-				c = color.RGBA{150,150,150,255}
-			case "fox":
-				c = color.RGBA{255,100,100,255}
-			case "grass":
-				intensity := uint8(entity.GetEnergy() * 2.5)
-				if intensity > 255 {
-					intensity = 255
+			info, ok := entities.LookupSpecies(entity.GetSpecies())
+			if !ok {
+				continue
+			}
+			c := info.ColorOf(entity)
+			if patient, ok := entity.(entities.Infectable); ok && patient.GetHealth() == entities.Infected {
+				c = infectedColor
+			}
+			if info.Structure {
+				// Homes are drawn as small squares so they stand out.
+				for sy := y - 1; sy <= y+1; sy++ {
+					for sx := x - 1; sx <= x+1; sx++ {
+						img.Set(sx, sy, c)
+					}
 				}
-				c = color.RGBA{0, intensity, 0, 255}
+				continue
 			}
-
 			img.Set(x, y, c)
 		}
 	}
+	if g.showTerritories {
+		g.drawTerritories(img, w, h)
+	}
 	return img
 }
 
+// drawTerritories outlines every territory in its owner's color, as
+// they were after the last tick.
+func (g *GUI) drawTerritories(img *image.RGBA, w, h int) {
+	sx := float64(w) / float64(g.world.Width)
+	sy := float64(h) / float64(g.world.Height)
+	for _, info := range entities.AnimalSpecies() {
+		for _, t := range g.territories[info.Name] {
+			steps := max(int(2*math.Pi*t.Radius*math.Max(sx, sy)), 16)
+			// On a torus a territory across an edge is drawn on both sides;
+			// the parts off the image are clipped.
+			for _, center := range g.world.SearchCenters(t.Center, t.Radius) {
+				for i := 0; i < steps; i++ {
+					angle := 2 * math.Pi * float64(i) / float64(steps)
+					x := (center.X + t.Radius*math.Cos(angle)) * sx
+					y := (center.Y + t.Radius*math.Sin(angle)) * sy
+					img.Set(int(math.Floor(x)), int(math.Floor(y)), info.Color)
+				}
+			}
+			img.Set(int(t.Center.X*sx), int(t.Center.Y*sy), info.Color)
+		}
+	}
+}
+
+// drawGround paints the terrain, black without one, and the grass field
+// on top of it, each cell shaded by its biomass relative to the largest
+// possible capacity.
+func (g *GUI) drawGround(img *image.RGBA, w, h int) {
+	ground, field := g.world.Terrain, g.world.Field
+
+	var shades []uint8
+	if field != nil {
+		full := g.world.Config.Grass.MaxAmountMax
+		shades = make([]uint8, len(field.Biomass))
+		for i, biomass := range field.Biomass {
+			shades[i] = uint8(math.Min(biomass/full, 1) * 150)
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		v := float64(y) / float64(h)
+		for x := 0; x < w; x++ {
+			u := float64(x) / float64(w)
+
+			c := color.RGBA{0, 0, 0, 255}
+			if ground != nil {
+				c = ground.At(u, v).Color()
+			}
+			if field != nil {
+				cell := field.Cell(geom.Point{X: u * float64(g.world.Width), Y: v * float64(g.world.Height)})
+				if shades[cell] > c.G {
+					c.G = shades[cell]
+				}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
 func (g *GUI) drawChart(w, h int) image.Image {
 	img := image.NewRGBA(image.Rect(0, 0, w, h))
 	
@@ -224,35 +346,36 @@ func (g *GUI) drawChart(w, h int) image.Image {
 		}
 	}
 	
-	if len(g.rabbitHistory) < 2 {
+	if g.historyLen < 2 {
 		return img
 	}
 	
-	maxPop := 1
-	for _, count := range g.rabbitHistory {
-		if count > maxPop { maxPop = count }
-	}
-	for _, count := range g.foxHistory {
-		if count > maxPop { maxPop = count }
+	// Plants vastly outnumber animals, so their line is drawn at a tenth.
+	species := entities.AllSpecies()
+	scaled := make(map[string][]int, len(species))
+	for _, info := range species {
+		data := g.history[info.Name]
+		if !info.Animal {
+			plants := make([]int, len(data))
+			for i, count := range data {
+				plants[i] = count / 10
+			}
+			data = plants
+		}
+		scaled[info.Name] = data
 	}
-	for _, count := range g.grassHistory {
-		if count/10 > maxPop { maxPop = count/10 }
+
+	maxPop := 1
+	for _, data := range scaled {
+		for _, count := range data {
+			if count > maxPop { maxPop = count }
+		}
 	}
 	
-	if maxPop == 0 { maxPop = 1 }
-	
-	g.drawLine(img, g.rabbitHistory, color.RGBA{150, 150, 150, 255}, w, h, maxPop)
-	g.drawLine(img, g.foxHistory, color.RGBA{255, 0, 0, 255}, w, h, maxPop)
-	
-	grassScaled := make([]int, len(g.grassHistory))
-	for i, count := range g.grassHistory {
-		grassScaled[i] = count / 10
+	for i, info := range species {
+		g.drawLine(img, scaled[info.Name], info.Color, w, h, maxPop)
+		img.Set(10, 10+10*i, info.Color)
 	}
-	g.drawLine(img, grassScaled, color.RGBA{0, 150, 0, 255}, w, h, maxPop)
-	
-	img.Set(10, 10, color.RGBA{150, 150, 150, 255})
-	img.Set(10, 20, color.RGBA{255, 0, 0, 255})
-	img.Set(10, 30, color.RGBA{0, 150, 0, 255})
 	
 	return img
 }
@@ -295,28 +418,113 @@ func abs(x int) int {
 	return x
 }
 
+// watchWorld subscribes to the births and deaths of the current world.
+func (g *GUI) watchWorld() {
+	if g.unsubscribe != nil {
+		g.unsubscribe()
+	}
+	g.deaths = make(map[interfaces.Cause]int)
+	g.unsubscribe = g.world.Subscribe(func(e world.Event) {
+		switch e := e.(type) {
+		case world.Born:
+			g.births++
+		case world.Died:
+			if info, ok := entities.LookupSpecies(e.Entity.GetSpecies()); ok && info.Animal {
+				g.deaths[e.Cause]++
+			}
+		}
+	})
+}
+
+func (g *GUI) clearHistory() {
+	g.history = make(map[string][]int)
+	g.historyLen = 0
+}
+
 func (g *GUI) updateStats() {
-	rabbits := g.world.CountRabbits()
-	foxes := g.world.CountFoxes()
-	grass := g.world.CountGrass()
-
-	g.rabbitHistory = append(g.rabbitHistory, rabbits)
-	g.foxHistory = append(g.foxHistory, foxes)
-	g.grassHistory = append(g.grassHistory, grass)
-
-	if len(g.rabbitHistory) > g.maxHistory {
-		g.rabbitHistory = g.rabbitHistory[1:]
-		g.foxHistory = g.foxHistory[1:]
-		g.grassHistory = g.grassHistory[1:]
+	populations := g.world.Populations()
+
+	stages := make(map[string]map[entities.LifeStage]int)
+	totalAge := make(map[string]int)
+	males := make(map[string]int)
+	for _, entity := range g.world.Entities {
+		if ageing, ok := entity.(entities.Ageing); ok && entity.IsAlive() {
+			species := entity.GetSpecies()
+			if stages[species] == nil {
+				stages[species] = make(map[entities.LifeStage]int)
+			}
+			stages[species][ageing.Stage()]++
+			totalAge[species] += ageing.GetAge()
+		}
+		if bearer, ok := entity.(entities.Bearer); ok && entity.IsAlive() && bearer.GetSex() == entities.Male {
+			males[entity.GetSpecies()]++
+		}
+	}
+
+	territories := make(map[string][]interfaces.Territory)
+	for _, info := range entities.AnimalSpecies() {
+		if list := g.world.Territories(info.Name); len(list) > 0 {
+			territories[info.Name] = append([]interfaces.Territory(nil), list...)
+		}
+	}
+
+	for name, count := range populations {
+		data := append(g.history[name], count)
+		if len(data) > g.maxHistory {
+			data = data[1:]
+		}
+		g.history[name] = data
+		g.historyLen = len(data)
+	}
+
+	events := fmt.Sprintf("Last tick: %d born", g.births)
+	for _, cause := range interfaces.Causes {
+		if g.deaths[cause] > 0 {
+			events += fmt.Sprintf(", %d died of %s", g.deaths[cause], cause)
+		}
 	}
+	g.births = 0
+	g.deaths = make(map[interfaces.Cause]int)
 
 	fyne.Do(func() {
-		maxGrass := g.world.MaxGrassCount
-		grassPercent := float64(grass) / float64(g.world.Width * g.world.Height) * 100
+		g.territories = territories
+		g.eventsLabel.SetText(events)
+
+		var parts []string
+		for _, info := range entities.AnimalSpecies() {
+			part := fmt.Sprintf("%s: %d", info.Label, populations[info.Name])
+			if count := populations[info.Name]; count > 0 {
+				s := stages[info.Name]
+				part += fmt.Sprintf(" (%d/%d/%d, age %.0f, %d m/%d f)", s[entities.Juvenile], s[entities.Adult], s[entities.Senior],
+					float64(totalAge[info.Name])/float64(count), males[info.Name], count-males[info.Name])
+			}
+			parts = append(parts, part)
+		}
+
+		grass := populations["grass"]
 		totalEntities := len(g.world.Entities)
-		
-		g.statsLabel.SetText(fmt.Sprintf("Rabbits: %d, Foxes: %d, Grass: %d/%d (%.1f%%) | Total Entities: %d", 
-			rabbits, foxes, grass, maxGrass, grassPercent, totalEntities))
+
+		clock := fmt.Sprintf("Day %d, %s", g.world.Day(), g.world.TimeOfDay())
+		if g.world.IsNight() {
+			clock += " (night)"
+		}
+		if g.world.Config.Clock.SeasonLength > 0 {
+			season := g.world.Season().String()
+			clock = strings.ToUpper(season[:1]) + season[1:] + ", " + clock
+		}
+		summary := clock + " | " + strings.Join(parts, ", ")
+
+		if field := g.world.Field; field != nil {
+			grassPercent := float64(grass) / float64(len(field.Biomass)) * 100
+			g.statsLabel.SetText(fmt.Sprintf("%s, Grass: %.1f%% of cells, biomass %.0f | Total Entities: %d",
+				summary, grassPercent, field.Total(), totalEntities))
+		} else {
+			maxGrass := g.world.MaxGrassCount
+			grassPercent := float64(grass) / float64(g.world.Width * g.world.Height) * 100
+
+			g.statsLabel.SetText(fmt.Sprintf("%s, Grass: %d/%d (%.1f%%) | Total Entities: %d",
+				summary, grass, maxGrass, grassPercent, totalEntities))
+		}
 		g.gameCanvas.Refresh()
 		g.chart.Refresh()
 	})
@@ -350,7 +558,7 @@ func (g *GUI) startSimulation() {
 				return 
 			}
 			
-			if g.world == nil || g.world.QuadTree == nil {
+			if g.world == nil {
 				g.running = false
 				return
 			}
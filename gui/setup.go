@@ -7,29 +7,40 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
 )
 
+// defaultCounts pre-fills the setup page; other species start at zero.
+var defaultCounts = map[string]int{"rabbit": 20, "fox": 5}
+
 type SetupPage struct {
 	container *fyne.Container
 	
 	grassEntry *widget.Entry
-	rabbitEntry *widget.Entry
-	foxEntry *widget.Entry
+	animalEntries map[string]*widget.Entry
+	structureEntries map[string]*widget.Entry
+	structureCounts map[string]int
 	startBtn *widget.Button
+	openBtn *widget.Button
 	
-	onStartCallback func(grassCount, rabbitCount, foxCount int, spawnMode string)
+	onStartCallback func(grassCount int, counts map[string]int, spawnMode string)
+	onOpenCallback func()
 }
 
 type SetupConfig struct {
 	GrassCount  int
-	RabbitCount int
-	FoxCount    int
+	Counts      map[string]int
 	SpawnMode   string
 }
 
-func NewSetupPage(onStart func(grassCount, rabbitCount, foxCount int, spawnMode string)) *SetupPage {
+// NewSetupPage builds the setup page; structureCounts pre-fills the number
+// of each kind of structure.
+func NewSetupPage(structureCounts map[string]int, onStart func(grassCount int, counts map[string]int, spawnMode string), onOpen func()) *SetupPage {
 	setup := &SetupPage{
+		structureCounts: structureCounts,
 		onStartCallback: onStart,
+		onOpenCallback: onOpen,
 	}
 	
 	setup.buildUI()
@@ -42,8 +53,9 @@ func (s *SetupPage) buildUI() {
 
 **Instructions:**
 - Set initial grass coverage (1% to 60%)
-- Set the initial number of rabbits and foxes
-- All animals spawn randomly across the board
+- Set the initial number of each animal species
+- Set the number of burrows, dens and other homes
+- All animals and homes spawn randomly across the board
 - Grass grows randomly during simulation`))
 
 	s.grassEntry = widget.NewEntry()
@@ -57,30 +69,34 @@ func (s *SetupPage) buildUI() {
 	grassForm := container.NewBorder(nil, nil, 
 		widget.NewRichTextFromMarkdown("**Initial grass coverage (%):**"), nil, s.grassEntry)
 
-	s.rabbitEntry = widget.NewEntry()
-	s.rabbitEntry.SetText("20")
-	s.rabbitEntry.Validator = func(text string) error {
-		if val, err := strconv.Atoi(text); err != nil || val < 1 {
-			return fmt.Errorf("must be a number ≥ 1")
-		}
-		return nil
+	s.animalEntries = make(map[string]*widget.Entry)
+	formContainer := container.NewVBox(grassForm)
+
+	for _, info := range entities.AnimalSpecies() {
+		entry := countEntry(defaultCounts[info.Name])
+		s.animalEntries[info.Name] = entry
+
+		form := container.NewBorder(nil, nil,
+			widget.NewRichTextFromMarkdown("**"+info.Label+":**"), nil, entry)
+		formContainer.Add(widget.NewSeparator())
+		formContainer.Add(form)
 	}
-	rabbitForm := container.NewBorder(nil, nil, 
-		widget.NewRichTextFromMarkdown("**Rabbits:**"), nil, s.rabbitEntry)
-
-	s.foxEntry = widget.NewEntry()
-	s.foxEntry.SetText("5")
-	s.foxEntry.Validator = func(text string) error {
-		if val, err := strconv.Atoi(text); err != nil || val < 1 {
-			return fmt.Errorf("must be a number ≥ 1")
-		}
-		return nil
+
+	s.structureEntries = make(map[string]*widget.Entry)
+	for _, info := range entities.StructureKinds() {
+		entry := countEntry(s.structureCounts[info.Name])
+		s.structureEntries[info.Name] = entry
+
+		form := container.NewBorder(nil, nil,
+			widget.NewRichTextFromMarkdown("**"+info.Label+":**"), nil, entry)
+		formContainer.Add(widget.NewSeparator())
+		formContainer.Add(form)
 	}
-	foxForm := container.NewBorder(nil, nil, 
-		widget.NewRichTextFromMarkdown("**Foxes:**"), nil, s.foxEntry)
 
 	spawnInfo := widget.NewCard("Spawn Info", "", 
-		widget.NewRichTextFromMarkdown(`**Animal Spawning:** All rabbits and foxes spawn randomly across the board
+		widget.NewRichTextFromMarkdown(`**Animal Spawning:** All animals spawn randomly across the board
+
+**Homes:** Rabbits hide and rest in burrows, foxes keep to the range around their den. Animals settle in the nearest home they see and give birth there
 
 **Grass Growth:** Grass spawns initially at the specified percentage, then grows randomly during simulation with 0.2% chance per empty cell each tick
 
@@ -89,15 +105,15 @@ func (s *SetupPage) buildUI() {
 	s.startBtn = widget.NewButton("Start Simulation", s.onStartClicked)
 	s.startBtn.Importance = widget.HighImportance
 
-	formContainer := container.NewVBox(
-		grassForm,
-		widget.NewSeparator(),
-		rabbitForm,
-		widget.NewSeparator(), 
-		foxForm,
-		widget.NewSeparator(),
-		s.startBtn,
-	)
+	s.openBtn = widget.NewButton("Open Saved Simulation", func() {
+		if s.onOpenCallback != nil {
+			s.onOpenCallback()
+		}
+	})
+
+	formContainer.Add(widget.NewSeparator())
+	formContainer.Add(s.startBtn)
+	formContainer.Add(s.openBtn)
 
 	leftColumn := container.NewVBox(
 		title,
@@ -114,23 +130,38 @@ func (s *SetupPage) buildUI() {
 	s.container = container.NewCenter(mainContent)
 }
 
+// countEntry is an entry for a count of zero or more, set to value.
+func countEntry(value int) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetText(strconv.Itoa(value))
+	entry.Validator = func(text string) error {
+		if val, err := strconv.Atoi(text); err != nil || val < 0 {
+			return fmt.Errorf("must be a number ≥ 0")
+		}
+		return nil
+	}
+	return entry
+}
+
 func (s *SetupPage) onStartClicked() {
 	if err := s.grassEntry.Validate(); err != nil {
 		return
 	}
-	if err := s.rabbitEntry.Validate(); err != nil {
-		return
+	for _, entry := range s.animalEntries {
+		if err := entry.Validate(); err != nil {
+			return
+		}
 	}
-	if err := s.foxEntry.Validate(); err != nil {
-		return
+	for _, entry := range s.structureEntries {
+		if err := entry.Validate(); err != nil {
+			return
+		}
 	}
 
-	grassPercentage, _ := strconv.ParseFloat(s.grassEntry.Text, 64)
-	rabbitCount, _ := strconv.Atoi(s.rabbitEntry.Text)
-	foxCount, _ := strconv.Atoi(s.foxEntry.Text)
+	config := s.GetConfig()
 
 	if s.onStartCallback != nil {
-		s.onStartCallback(int(grassPercentage*100), rabbitCount, foxCount, "Random")
+		s.onStartCallback(config.GrassCount, config.Counts, config.SpawnMode)
 	}
 }
 
@@ -140,13 +171,18 @@ func (s *SetupPage) GetContainer() *fyne.Container {
 
 func (s *SetupPage) GetConfig() SetupConfig {
 	grassPercentage, _ := strconv.ParseFloat(s.grassEntry.Text, 64)
-	rabbitCount, _ := strconv.Atoi(s.rabbitEntry.Text)
-	foxCount, _ := strconv.Atoi(s.foxEntry.Text)
+
+	counts := make(map[string]int, len(s.animalEntries)+len(s.structureEntries))
+	for name, entry := range s.animalEntries {
+		counts[name], _ = strconv.Atoi(entry.Text)
+	}
+	for name, entry := range s.structureEntries {
+		counts[name], _ = strconv.Atoi(entry.Text)
+	}
 	
 	return SetupConfig{
 		GrassCount:  int(grassPercentage * 100),
-		RabbitCount: rabbitCount,
-		FoxCount:    foxCount,
+		Counts:      counts,
 		SpawnMode:   "Random",
 	}
 }
\ No newline at end of file
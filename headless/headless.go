@@ -0,0 +1,117 @@
+package headless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/world"
+)
+
+// Options describes the initial population and length of a headless run.
+type Options struct {
+	Ticks           int
+	GrassPercentage float64
+	// Counts is the initial number of animals per species and of
+	// structures per kind.
+	Counts map[string]int
+	// Resume continues from the world's current state, e.g. a loaded
+	// snapshot, instead of seeding a new population.
+	Resume bool
+	// Traits adds the mean and variance of every heritable trait of each
+	// animal species to the output.
+	Traits bool
+	// CheckEnergy stops the run with an error as soon as the world's
+	// energy ledger does not balance.
+	CheckEnergy bool
+}
+
+// Run seeds the world the same way the setup page does, or resumes it
+// with opts.Resume, and advances it until opts.Ticks ticks have passed or
+// an animal species that was seeded, or alive when resuming, dies out.
+// Population counts are written to out as CSV, one line per tick with a
+// column per registered species. It returns the number of ticks that
+// were simulated.
+func Run(w *world.World, opts Options, out io.Writer) (int, error) {
+	seeded := opts.Counts
+	if opts.Resume {
+		seeded = w.Populations()
+	} else {
+		w.Reset()
+		w.Populate(int(opts.GrassPercentage*100), opts.Counts)
+	}
+
+	species := entities.AllSpecies()
+
+	buf := bufio.NewWriter(out)
+	header := []string{"tick"}
+	for _, info := range species {
+		header = append(header, info.Name)
+	}
+	if opts.Traits {
+		for _, info := range entities.AnimalSpecies() {
+			for _, trait := range entities.TraitNames {
+				header = append(header, info.Name+"_"+trait+"_mean", info.Name+"_"+trait+"_var")
+			}
+		}
+	}
+	fmt.Fprintln(buf, strings.Join(header, ","))
+	writeRow(buf, w, species, opts)
+
+	tick := 0
+	for tick < opts.Ticks {
+		w.Update()
+		tick++
+		if opts.CheckEnergy {
+			if err := w.Ledger.Check(); err != nil {
+				buf.Flush()
+				return tick, fmt.Errorf("tick %d: %w", w.Tick, err)
+			}
+		}
+		populations := writeRow(buf, w, species, opts)
+
+		if extinct(populations, seeded) {
+			break
+		}
+	}
+
+	return tick, buf.Flush()
+}
+
+func writeRow(out io.Writer, w *world.World, species []entities.SpeciesInfo, opts Options) map[string]int {
+	populations := w.Populations()
+
+	fmt.Fprintf(out, "%d", w.Tick)
+	for _, info := range species {
+		fmt.Fprintf(out, ",%d", populations[info.Name])
+	}
+	if opts.Traits {
+		stats := w.TraitStats()
+		for _, info := range entities.AnimalSpecies() {
+			s, ok := stats[info.Name]
+			mean, variance := s.Mean.Traits(), s.Variance.Traits()
+			for i := range entities.TraitNames {
+				if ok {
+					fmt.Fprintf(out, ",%.4f,%.4f", mean[i], variance[i])
+				} else {
+					fmt.Fprint(out, ",,")
+				}
+			}
+		}
+	}
+	fmt.Fprintln(out)
+
+	return populations
+}
+
+func extinct(populations, seeded map[string]int) bool {
+	for name, count := range seeded {
+		info, ok := entities.LookupSpecies(name)
+		if ok && info.Animal && count > 0 && populations[name] == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package interfaces
+
+import "github.com/j-bisew/foxes-rabbits-simulation/geom"
+
+// ActionKind says which interaction, if any, an action asks for.
+type ActionKind int
+
+const (
+	Idle ActionKind = iota
+	Eat
+	Mate
+	// Graze eats from the grass field at At instead of from an entity.
+	Graze
+	// Flee is a panicked run away from predators; it costs extra energy.
+	Flee
+	// Fight challenges Target, an intruder in the actor's territory.
+	Fight
+)
+
+// Action is what an entity decided to do during the sense phase of a tick:
+// a movement, optionally followed by eating, mating with or fighting
+// Target, or by grazing the grass field. The
+// world applies the movement and then grants or refuses the interaction.
+type Action struct {
+	Kind   ActionKind
+	DX, DY float64
+	Target Entity
+	// Distance to Target when the action was decided; the closest claimant
+	// wins when several entities want the same target.
+	Distance float64
+	At       geom.Point
+}
+
+// Feeder is implemented by entities that gain energy from eating.
+type Feeder interface {
+	Feed(energy float64)
+}
+
+// Breeder is implemented by entities that can mate.
+type Breeder interface {
+	CanReproduce() bool
+	// CanMateWith reports whether partner is a possible mate at all, e.g.
+	// of the right sex, regardless of whether either is ready.
+	CanMateWith(partner Entity) bool
+	// Fertility is the chance, between 0 and 1, that a mating bears young.
+	Fertility() float64
+	// Mated applies the cost of mating and starts the cooldown; it is
+	// called on both partners.
+	Mated()
+}
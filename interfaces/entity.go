@@ -2,13 +2,48 @@ package interfaces
 
 import "github.com/j-bisew/foxes-rabbits-simulation/geom"
 
+// Cause explains why an entity died.
+type Cause string
+
+const (
+	Starvation Cause = "starvation"
+	Predation  Cause = "predation"
+	Grazed     Cause = "grazed"
+	OldAge     Cause = "senescence"
+	Disease    Cause = "disease"
+)
+
+// Causes lists every cause of death in a fixed order.
+var Causes = []Cause{Starvation, Predation, Grazed, OldAge, Disease}
+
+// Flow names what an animal pays energy for while it acts.
+type Flow string
+
+const (
+	Upkeep   Flow = "upkeep"
+	Sickness Flow = "sickness"
+	Fleeing  Flow = "fleeing"
+	// Gestation is what a pregnant female sets aside for her litter; the
+	// energy stays with her until the young are born.
+	Gestation Flow = "gestation"
+)
+
 type Entity interface {
-	Update(WorldInterface)
+	// Decide chooses this tick's action. It runs concurrently with the
+	// decisions of other entities and must not change any state.
+	Decide(WorldInterface) Action
+	// Act applies the entity's own part of the action: upkeep such as
+	// metabolism or growth, and movement.
+	Act(WorldInterface, Action)
+
+	GetID() uint64
+	SetID(uint64)
 	GetPosition() geom.Point
 	GetSpecies() string
-	GetFoodType() string
+	GetDiet() []string
 	IsAlive() bool
 	GetEnergy() float64
 	UpdateEnergy(float64)
-	Kill()
-}
\ No newline at end of file
+	Kill(cause Cause)
+	GetDeathCause() Cause
+}
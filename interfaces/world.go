@@ -1,10 +1,41 @@
 package interfaces
 
-import "github.com/j-bisew/foxes-rabbits-simulation/geom"
+import (
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+)
+
+// Territory is the ground within Radius of Center that Owner claims.
+type Territory struct {
+	Owner  Entity
+	Center geom.Point
+	Radius float64
+}
 
 type WorldInterface interface {
 	FindNearbyEntities(pos geom.Point, radius float64, species string) []Entity
-	CreateOffspring(parent1, parent2 Entity) Entity
+	FindNearest(pos geom.Point, radius float64, k int, species string, filter func(Entity) bool) []Entity
+	// PredatorsOf lists the species whose diet includes species.
+	PredatorsOf(species string) []string
+	// FindPasture returns the closest grazable spot of a field-grown species.
+	FindPasture(pos geom.Point, radius float64, species string) (geom.Point, bool)
 	IsValidPosition(x, y float64) bool
+	Confine(p geom.Point) geom.Point
+	Offset(from, to geom.Point) (dx, dy float64)
+	// Mobility is the speed factor of the ground at p, zero if impassable.
+	Mobility(p geom.Point) float64
+	// IsNight reports whether it is night on the world clock.
+	IsNight() bool
+	// Climate returns how the current season scales growth and upkeep.
+	Climate() config.Season
+	// Territories lists the territories of species' animals as they were
+	// at the start of the tick, by owner ID.
+	Territories(species string) []Territory
 	ConsumeFood(entity Entity, eater Entity) float64
+	// Charge books energy an entity paid for flow in Act. It must not be
+	// called while deciding.
+	Charge(flow Flow, amount float64)
+	Random() *rand.Rand
 }
\ No newline at end of file
@@ -2,19 +2,241 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
 	"github.com/j-bisew/foxes-rabbits-simulation/gui"
+	"github.com/j-bisew/foxes-rabbits-simulation/headless"
+	"github.com/j-bisew/foxes-rabbits-simulation/metrics"
 	"github.com/j-bisew/foxes-rabbits-simulation/world"
 )
 
+// speciesCounts collects repeated -spawn name=count flags, for animals
+// and structures.
+type speciesCounts map[string]int
+
+func (c speciesCounts) String() string {
+    parts := make([]string, 0, len(c))
+    for name, count := range c {
+        parts = append(parts, fmt.Sprintf("%s=%d", name, count))
+    }
+    return strings.Join(parts, ",")
+}
+
+func (c speciesCounts) Set(value string) error {
+    name, countText, ok := strings.Cut(value, "=")
+    if !ok {
+        return fmt.Errorf("expected name=count, got %q", value)
+    }
+    if info, ok := entities.LookupSpecies(name); !ok || !info.Animal && !info.Structure {
+        return fmt.Errorf("unknown animal species or structure %q", name)
+    }
+    count, err := strconv.Atoi(countText)
+    if err != nil || count < 0 {
+        return fmt.Errorf("invalid count in %q", value)
+    }
+    c[name] = count
+    return nil
+}
+
+// with returns base overridden by the counts given on the command line.
+func (c speciesCounts) with(base map[string]int) map[string]int {
+    for name, count := range c {
+        base[name] = count
+    }
+    return base
+}
+
 func main() {
     width := flag.Int("w", 400, "width of board")
     height := flag.Int("h", 200, "height of board")
+    configPath := flag.String("config", "", "path to a JSON file with simulation parameters")
+    printConfig := flag.Bool("print-config", false, "print the effective configuration as JSON and exit")
+    boundary := flag.String("boundary", "", "board edge policy: clamp, reflect or torus (overrides the config)")
+    seed := flag.Uint64("seed", 0, "random seed; 0 picks one from the clock")
+    terrainMap := flag.String("terrain", "", "terrain map: \"noise\" or a .png or ASCII map file (overrides the config)")
+    outbreak := flag.String("outbreak", "", "infect animals at a tick, as species=count@tick (overrides the config)")
+
+    headlessMode := flag.Bool("headless", false, "run without a window and print populations to stdout")
+    ticks := flag.Int("ticks", 1000, "number of ticks to simulate in headless mode")
+    grass := flag.Float64("grass", 30, "initial grass coverage in percent (headless mode)")
+    rabbits := flag.Int("rabbits", 20, "initial number of rabbits (headless mode)")
+    foxes := flag.Int("foxes", 5, "initial number of foxes (headless mode)")
+    loadPath := flag.String("load", "", "resume from a snapshot file instead of seeding a new world")
+    savePath := flag.String("save", "", "write a snapshot to this file when the headless run ends")
+    metricsPath := flag.String("metrics", "", "stream per-tick metrics to this .csv or .jsonl file")
+    traits := flag.Bool("traits", false, "add per-species trait means and variances to the output (headless mode)")
+    checkEnergy := flag.Bool("check-energy", false, "fail as soon as the animals' energy does not balance (headless mode)")
+    spawn := speciesCounts{}
+    flag.Var(spawn, "spawn", "initial count for any species or structure as name=count, repeatable (headless mode)")
 
     flag.Parse()
 
-    world := world.NewWorld(*width, *height)
+    cfg := config.Default()
+    if *configPath != "" {
+        loaded, err := config.Load(*configPath)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(2)
+        }
+        cfg = loaded
+    }
+
+    // Explicit -w/-h/-boundary/-terrain/-outbreak flags win over the config file.
+    var flagErr error
+    flag.Visit(func(f *flag.Flag) {
+        switch f.Name {
+        case "w":
+            cfg.World.Width = *width
+        case "h":
+            cfg.World.Height = *height
+        case "boundary":
+            cfg.World.Boundary = *boundary
+        case "terrain":
+            cfg.Terrain.Map = *terrainMap
+        case "outbreak":
+            o, err := parseOutbreak(*outbreak)
+            if err != nil {
+                flagErr = fmt.Errorf("-outbreak: %w", err)
+                return
+            }
+            cfg.Outbreak = o
+        }
+    })
+    if flagErr != nil {
+        fmt.Fprintln(os.Stderr, flagErr)
+        os.Exit(2)
+    }
+    if err := cfg.Validate(); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+
+    if *printConfig {
+        if err := cfg.Write(os.Stdout); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        return
+    }
+
+    if *seed == 0 {
+        *seed = uint64(time.Now().UnixNano())
+    }
+
+    world := world.NewWorld(cfg, *seed)
+    if err := world.LoadTerrain(); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+    if *loadPath != "" {
+        loaded, err := loadWorld(*loadPath)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        world = loaded
+    }
+
+    var recorder metrics.StreamRecorder
+    if *metricsPath != "" {
+        r, err := metrics.Open(*metricsPath)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        recorder = r
+        world.AddRecorder(recorder)
+    }
+
+    if *headlessMode {
+        fmt.Fprintf(os.Stderr, "seed: %d\n", world.Seed)
+        counts := map[string]int{"rabbit": *rabbits, "fox": *foxes}
+        for kind, count := range cfg.Structures.Counts {
+            counts[kind] = count
+        }
+        opts := headless.Options{
+            Ticks: *ticks,
+            GrassPercentage: *grass,
+            Counts: spawn.with(counts),
+            Resume: *loadPath != "",
+            Traits: *traits,
+            CheckEnergy: *checkEnergy,
+        }
+        if _, err := headless.Run(world, opts, os.Stdout); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        if err := closeRecorder(recorder); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        if *savePath != "" {
+            if err := saveWorld(world, *savePath); err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                os.Exit(1)
+            }
+        }
+        return
+    }
     
     gui := gui.NewGUI(world)
+    if *loadPath != "" {
+        gui.OpenWorld(world)
+    }
     gui.Run()
-}
\ No newline at end of file
+
+    if err := closeRecorder(recorder); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+}
+
+// parseOutbreak reads species=count@tick.
+func parseOutbreak(value string) (config.Outbreak, error) {
+    species, rest, ok1 := strings.Cut(value, "=")
+    countText, tickText, ok2 := strings.Cut(rest, "@")
+    if !ok1 || !ok2 {
+        return config.Outbreak{}, fmt.Errorf("expected species=count@tick, got %q", value)
+    }
+    count, err1 := strconv.Atoi(countText)
+    tick, err2 := strconv.Atoi(tickText)
+    if err1 != nil || err2 != nil {
+        return config.Outbreak{}, fmt.Errorf("invalid count or tick in %q", value)
+    }
+    return config.Outbreak{Tick: tick, Species: species, Count: count}, nil
+}
+
+func closeRecorder(r metrics.StreamRecorder) error {
+    if r == nil {
+        return nil
+    }
+    return r.Close()
+}
+
+
+func loadWorld(path string) (*world.World, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    return world.Load(f)
+}
+
+func saveWorld(w *world.World, path string) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if err := w.Save(f); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+	"github.com/j-bisew/foxes-rabbits-simulation/world"
+)
+
+// StreamRecorder is a world.Recorder that writes every tick to a stream.
+// Write errors are kept and reported by Close.
+type StreamRecorder interface {
+	world.Recorder
+	Close() error
+}
+
+// Open creates a recorder writing to path, choosing CSV or JSON Lines by
+// the file extension (.csv, .jsonl or .ndjson).
+func Open(path string) (StreamRecorder, error) {
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		format = "csv"
+	case ".jsonl", ".ndjson":
+		format = "jsonl"
+	default:
+		return nil, fmt.Errorf("metrics: cannot tell format of %s, use .csv or .jsonl", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	if format == "csv" {
+		return NewCSV(f), nil
+	}
+	return NewJSONL(f), nil
+}
+
+type csvRecorder struct {
+	out     *bufio.Writer
+	closer  io.Closer
+	species []string
+	animal  []bool
+	err     error
+}
+
+// NewCSV returns a recorder writing one CSV row per tick. The columns are
+// fixed by the species registered when the first tick is recorded.
+// If out is an io.Closer it is closed by Close.
+func NewCSV(out io.Writer) StreamRecorder {
+	closer, _ := out.(io.Closer)
+	return &csvRecorder{out: bufio.NewWriter(out), closer: closer}
+}
+
+func (r *csvRecorder) Record(stats *world.TickStats) {
+	if r.err != nil {
+		return
+	}
+
+	if r.species == nil {
+		header := []string{"tick", "season", "night"}
+		for _, info := range entities.AllSpecies() {
+			// Structures are not counted in the stats, so they get no columns.
+			if info.Structure {
+				continue
+			}
+			name := info.Name
+			r.species = append(r.species, name)
+			r.animal = append(r.animal, info.Animal)
+			header = append(header, name+"_count", name+"_energy_total", name+"_energy_mean", name+"_births")
+			for _, cause := range interfaces.Causes {
+				header = append(header, name+"_deaths_"+string(cause))
+			}
+			if info.Animal {
+				for _, stage := range entities.LifeStages {
+					header = append(header, name+"_"+stage.String())
+				}
+				header = append(header, name+"_age_mean", name+"_males", name+"_females", name+"_pregnant")
+				for _, health := range entities.HealthStates {
+					header = append(header, name+"_"+health.String())
+				}
+				header = append(header, name+"_infections", name+"_groups", name+"_group_size_mean", name+"_sheltered",
+					name+"_territories", name+"_territory_size_mean", name+"_territory_overlap", name+"_fights")
+			}
+		}
+		header = append(header, "grass_biomass",
+			"energy_held", "energy_eaten", "energy_upkeep", "energy_sickness", "energy_fleeing", "energy_gestation",
+			"energy_courtship", "energy_fights", "energy_died", "energy_imbalance")
+		_, r.err = fmt.Fprintln(r.out, strings.Join(header, ","))
+	}
+
+	row := []string{strconv.Itoa(stats.Tick), stats.Season, strconv.FormatBool(stats.Night)}
+	for i, name := range r.species {
+		s := stats.Species[name]
+		row = append(row,
+			strconv.Itoa(s.Count),
+			formatFloat(s.TotalEnergy),
+			formatFloat(s.MeanEnergy),
+			strconv.Itoa(s.Births),
+		)
+		for _, cause := range interfaces.Causes {
+			row = append(row, strconv.Itoa(s.Deaths[cause]))
+		}
+		if r.animal[i] {
+			for _, stage := range entities.LifeStages {
+				row = append(row, strconv.Itoa(s.Stages[stage.String()]))
+			}
+			row = append(row, formatFloat(s.MeanAge), strconv.Itoa(s.Males), strconv.Itoa(s.Females), strconv.Itoa(s.Pregnant))
+			for _, health := range entities.HealthStates {
+				row = append(row, strconv.Itoa(s.Health[health.String()]))
+			}
+			row = append(row, strconv.Itoa(s.Infections), strconv.Itoa(s.Groups), formatFloat(s.MeanGroupSize), strconv.Itoa(s.Sheltered),
+				strconv.Itoa(s.Territories), formatFloat(s.MeanTerritorySize), formatFloat(s.TerritoryOverlap), strconv.Itoa(s.Fights))
+		}
+	}
+	e := stats.Energy
+	row = append(row, formatFloat(stats.GrassBiomass),
+		formatFloat(e.Held), formatFloat(e.Eaten), formatFloat(e.Upkeep), formatFloat(e.Sickness), formatFloat(e.Fleeing), formatFloat(e.Gestation),
+		formatFloat(e.Courtship), formatFloat(e.Fights), formatFloat(e.Died),
+		strconv.FormatFloat(e.Imbalance, 'g', 4, 64))
+
+	if r.err == nil {
+		_, r.err = fmt.Fprintln(r.out, strings.Join(row, ","))
+	}
+}
+
+func (r *csvRecorder) Close() error {
+	return finish(r.out, r.closer, r.err)
+}
+
+type jsonlRecorder struct {
+	out    *bufio.Writer
+	closer io.Closer
+	enc    *json.Encoder
+	err    error
+}
+
+// NewJSONL returns a recorder writing one JSON object per tick and line.
+// If out is an io.Closer it is closed by Close.
+func NewJSONL(out io.Writer) StreamRecorder {
+	closer, _ := out.(io.Closer)
+	buf := bufio.NewWriter(out)
+	return &jsonlRecorder{out: buf, closer: closer, enc: json.NewEncoder(buf)}
+}
+
+func (r *jsonlRecorder) Record(stats *world.TickStats) {
+	if r.err == nil {
+		r.err = r.enc.Encode(stats)
+	}
+}
+
+func (r *jsonlRecorder) Close() error {
+	return finish(r.out, r.closer, r.err)
+}
+
+func finish(out *bufio.Writer, closer io.Closer, err error) error {
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if closer != nil {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/world"
+)
+
+func TestCSVColumns(t *testing.T) {
+	cfg := config.Default()
+	w := world.NewWorld(cfg, 1)
+	var buf bytes.Buffer
+	r := NewCSV(&buf)
+	w.AddRecorder(r)
+
+	counts := map[string]int{"rabbit": 20, "fox": 5}
+	for kind, count := range cfg.Structures.Counts {
+		counts[kind] = count
+	}
+	w.Reset()
+	w.Populate(3000, counts)
+	for i := 0; i < 3; i++ {
+		w.Update()
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want a header and 3 rows", len(lines))
+	}
+	header := strings.Split(lines[0], ",")
+	for _, row := range lines[1:] {
+		if n := len(strings.Split(row, ",")); n != len(header) {
+			t.Errorf("row has %d fields, header %d", n, len(header))
+		}
+	}
+
+	for _, info := range entities.StructureKinds() {
+		for _, column := range header {
+			if strings.HasPrefix(column, info.Name+"_") {
+				t.Errorf("structure %s has a column %s", info.Name, column)
+			}
+		}
+	}
+	for _, info := range entities.AnimalSpecies() {
+		if !strings.Contains(lines[0], ","+info.Name+"_count,") {
+			t.Errorf("no count column for %s", info.Name)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package quadtree
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Filter selects the entities a query returns. A nil Filter accepts all.
+type Filter func(Entity) bool
+
+func BySpecies(species string) Filter {
+	return func(e Entity) bool { return e.GetSpecies() == species }
+}
+
+func Alive(e Entity) bool { return e.IsAlive() }
+
+// EnergyAbove keeps entities with more than min energy.
+func EnergyAbove(min float64) Filter {
+	return func(e Entity) bool { return e.GetEnergy() > min }
+}
+
+// And combines filters; nil filters are skipped.
+func And(filters ...Filter) Filter {
+	return func(e Entity) bool {
+		for _, f := range filters {
+			if f != nil && !f(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Neighbor is an entity found by Nearest together with its distance.
+type Neighbor struct {
+	Entity   Entity
+	Distance float64
+}
+
+// QueryCircle appends to found every entity within radius of center that
+// passes filter.
+func (qt *QuadTree) QueryCircle(center Point, radius float64, filter Filter, found *[]Entity) {
+	if rectDistance(qt.boundary, center) > radius {
+		return
+	}
+
+	if !qt.divided {
+		for _, ep := range qt.points {
+			if distance(ep.Point, center) <= radius && (filter == nil || filter(ep.Entity)) {
+				*found = append(*found, ep.Entity)
+			}
+		}
+		return
+	}
+
+	qt.ne.QueryCircle(center, radius, filter, found)
+	qt.nw.QueryCircle(center, radius, filter, found)
+	qt.se.QueryCircle(center, radius, filter, found)
+	qt.sw.QueryCircle(center, radius, filter, found)
+}
+
+// Nearest returns up to k entities passing filter, closest first.
+func (qt *QuadTree) Nearest(point Point, k int, filter Filter) []Neighbor {
+	return qt.NearestWithin(point, k, math.Inf(1), filter)
+}
+
+// NearestWithin is Nearest limited to entities within radius of point.
+// The search is best-first: nodes are visited in order of their distance
+// to point and the search stops as soon as k entities are found, so far
+// away parts of the tree are never opened. Entities at equal distance
+// are returned in ID order.
+func (qt *QuadTree) NearestWithin(point Point, k int, radius float64, filter Filter) []Neighbor {
+	if k <= 0 {
+		return nil
+	}
+
+	var result []Neighbor
+	queue := &searchQueue{}
+	heap.Push(queue, searchItem{node: qt, distance: rectDistance(qt.boundary, point)})
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(searchItem)
+		if item.distance > radius {
+			break
+		}
+
+		if item.node == nil {
+			result = append(result, Neighbor{Entity: item.entity, Distance: item.distance})
+			if len(result) == k {
+				break
+			}
+			continue
+		}
+
+		node := item.node
+		if !node.divided {
+			for _, ep := range node.points {
+				if filter != nil && !filter(ep.Entity) {
+					continue
+				}
+				if d := distance(ep.Point, point); d <= radius {
+					heap.Push(queue, searchItem{entity: ep.Entity, distance: d})
+				}
+			}
+			continue
+		}
+
+		for _, child := range []*QuadTree{node.ne, node.nw, node.se, node.sw} {
+			if child.count > 0 {
+				if d := rectDistance(child.boundary, point); d <= radius {
+					heap.Push(queue, searchItem{node: child, distance: d})
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// searchItem is either a node still to open or an entity found.
+type searchItem struct {
+	node     *QuadTree
+	entity   Entity
+	distance float64
+}
+
+type searchQueue []searchItem
+
+func (q searchQueue) Len() int { return len(q) }
+
+// Less orders by distance; at equal distance nodes come first, so every
+// entity at that distance is known before any is returned, then IDs.
+func (q searchQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if a.distance != b.distance {
+		return a.distance < b.distance
+	}
+	if (a.node == nil) != (b.node == nil) {
+		return a.node != nil
+	}
+	if a.node == nil {
+		return a.entity.GetID() < b.entity.GetID()
+	}
+	return false
+}
+
+func (q searchQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *searchQueue) Push(x any) { *q = append(*q, x.(searchItem)) }
+
+func (q *searchQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+func distance(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// rectDistance is the distance from p to the closest point of r.
+func rectDistance(r Rectangle, p Point) float64 {
+	dx := math.Max(0, math.Max(r.X-p.X, p.X-(r.X+r.Width)))
+	dy := math.Max(0, math.Max(r.Y-p.Y, p.Y-(r.Y+r.Height)))
+	return math.Hypot(dx, dy)
+}
@@ -0,0 +1,195 @@
+package quadtree
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"testing"
+)
+
+// bruteNearest is what NearestWithin must return: every entity within
+// radius that passes filter, by distance and then ID, cut to k.
+func bruteNearest(at map[Entity]Point, point Point, k int, radius float64, filter Filter) []Neighbor {
+	var all []Neighbor
+	for e, p := range at {
+		if d := distance(p, point); d <= radius && (filter == nil || filter(e)) {
+			all = append(all, Neighbor{Entity: e, Distance: d})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Distance != all[j].Distance {
+			return all[i].Distance < all[j].Distance
+		}
+		return all[i].Entity.GetID() < all[j].Entity.GetID()
+	})
+	if len(all) > k {
+		all = all[:k]
+	}
+	return all
+}
+
+func sameNeighbors(a, b []Neighbor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Entity != b[i].Entity || a[i].Distance != b[i].Distance {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQueryCircle(t *testing.T) {
+	rng := rand.New(rand.NewPCG(6, 6))
+	qt := NewQuadTree(4, board)
+	at := scatter(qt, 1000, rng)
+
+	for i := 0; i < 50; i++ {
+		center := randomPoint(rng)
+		radius := rng.Float64() * 120
+		var found []Entity
+		qt.QueryCircle(center, radius, nil, &found)
+
+		want := 0
+		for _, p := range at {
+			if distance(p, center) <= radius {
+				want++
+			}
+		}
+		if len(found) != want {
+			t.Fatalf("QueryCircle(%v, %g) found %d entities, want %d", center, radius, len(found), want)
+		}
+		for _, e := range found {
+			if d := distance(at[e], center); d > radius {
+				t.Fatalf("QueryCircle(%v, %g) returned an entity %g away", center, radius, d)
+			}
+		}
+	}
+}
+
+// TestQueryCircleCorners checks that the corners of the bounding square,
+// which a square query would return, are left out.
+func TestQueryCircleCorners(t *testing.T) {
+	qt := NewQuadTree(4, board)
+	center := Point{X: 100, Y: 100}
+	inside := &testEntity{id: 1}
+	edge := &testEntity{id: 2}
+	corner := &testEntity{id: 3}
+	qt.Insert(Point{X: 103, Y: 104}, inside)
+	qt.Insert(Point{X: 110, Y: 100}, edge)
+	qt.Insert(Point{X: 109, Y: 109}, corner)
+
+	var found []Entity
+	qt.QueryCircle(center, 10, nil, &found)
+	if len(found) != 2 {
+		t.Fatalf("found %d entities, want the one inside and the one on the circle", len(found))
+	}
+	for _, e := range found {
+		if e == corner {
+			t.Fatal("QueryCircle returned the corner of the bounding square")
+		}
+	}
+}
+
+func TestNearestMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 7))
+	qt := NewQuadTree(4, board)
+	at := scatter(qt, 1000, rng)
+	for e := range at {
+		e.(*testEntity).energy = rng.Float64() * 10
+	}
+	filters := map[string]Filter{
+		"none":   nil,
+		"energy": EnergyAbove(5),
+	}
+
+	for i := 0; i < 100; i++ {
+		point := randomPoint(rng)
+		k := 1 + rng.IntN(20)
+		radius := rng.Float64() * 150
+		for name, filter := range filters {
+			got := qt.NearestWithin(point, k, radius, filter)
+			if want := bruteNearest(at, point, k, radius, filter); !sameNeighbors(got, want) {
+				t.Fatalf("%s: NearestWithin(%v, %d, %g) returned %d neighbours, want %d", name, point, k, radius, len(got), len(want))
+			}
+		}
+		got := qt.Nearest(point, k, nil)
+		if want := bruteNearest(at, point, k, math.Inf(1), nil); !sameNeighbors(got, want) {
+			t.Fatalf("Nearest(%v, %d) differs from a brute-force search", point, k)
+		}
+	}
+}
+
+func TestNearestTies(t *testing.T) {
+	qt := NewQuadTree(2, board)
+	center := Point{X: 200, Y: 200}
+	// Four entities 5 away in every direction, inserted in reverse ID
+	// order and spread over different nodes.
+	offsets := []Point{{X: 5, Y: 0}, {X: 0, Y: 5}, {X: -5, Y: 0}, {X: 0, Y: -5}}
+	for i, o := range offsets {
+		qt.Insert(Point{X: center.X + o.X, Y: center.Y + o.Y}, &testEntity{id: uint64(len(offsets) - i)})
+	}
+	qt.Insert(Point{X: 201, Y: 200}, &testEntity{id: 10})
+
+	got := qt.NearestWithin(center, 3, 10, nil)
+	if len(got) != 3 {
+		t.Fatalf("got %d neighbours, want 3", len(got))
+	}
+	if got[0].Entity.GetID() != 10 || got[1].Entity.GetID() != 1 || got[2].Entity.GetID() != 2 {
+		t.Fatalf("got IDs %d, %d, %d, want 10, 1, 2", got[0].Entity.GetID(), got[1].Entity.GetID(), got[2].Entity.GetID())
+	}
+}
+
+func TestNearestLimits(t *testing.T) {
+	qt := NewQuadTree(4, board)
+	for i := 0; i < 5; i++ {
+		qt.Insert(Point{X: 10 + float64(i), Y: 10}, &testEntity{id: uint64(i + 1)})
+	}
+	qt.Insert(Point{X: 300, Y: 300}, &testEntity{id: 6})
+
+	if got := qt.NearestWithin(Point{X: 10, Y: 10}, 0, 100, nil); got != nil {
+		t.Fatalf("k = 0 returned %d neighbours", len(got))
+	}
+	if got := qt.NearestWithin(Point{X: 10, Y: 10}, 1000, 100, nil); len(got) != 5 {
+		t.Fatalf("k above the number in range returned %d neighbours, want 5", len(got))
+	}
+	if got := qt.Nearest(Point{X: 10, Y: 10}, 1000, nil); len(got) != 6 {
+		t.Fatalf("unlimited k returned %d neighbours, want 6", len(got))
+	}
+	if got := qt.NearestWithin(Point{X: 10, Y: 10}, 3, 2, nil); len(got) != 3 || got[2].Distance != 2 {
+		t.Fatal("a neighbour exactly at the radius is left out")
+	}
+	if got := qt.NearestWithin(Point{X: 100, Y: 100}, 3, 50, nil); len(got) != 0 {
+		t.Fatalf("found %d neighbours with nothing in range", len(got))
+	}
+	if got := NewQuadTree(4, board).Nearest(Point{X: 1, Y: 1}, 3, nil); len(got) != 0 {
+		t.Fatalf("an empty tree returned %d neighbours", len(got))
+	}
+}
+
+func TestFilters(t *testing.T) {
+	fox := &testEntity{id: 1, species: "fox", energy: 50}
+	rabbit := &testEntity{id: 2, species: "rabbit", energy: 5}
+	dead := &testEntity{id: 3, species: "rabbit", energy: 50, dead: true}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []bool
+	}{
+		{"species", BySpecies("rabbit"), []bool{false, true, true}},
+		{"alive", Alive, []bool{true, true, false}},
+		{"energy", EnergyAbove(10), []bool{true, false, true}},
+		{"and", And(BySpecies("rabbit"), Alive), []bool{false, true, false}},
+		{"and with nil", And(nil, EnergyAbove(10), nil), []bool{true, false, true}},
+		{"empty and", And(), []bool{true, true, true}},
+	}
+	for _, tt := range tests {
+		for i, e := range []Entity{fox, rabbit, dead} {
+			if got := tt.filter(e); got != tt.want[i] {
+				t.Errorf("%s: filter(entity %d) = %v, want %v", tt.name, e.GetID(), got, tt.want[i])
+			}
+		}
+	}
+}
@@ -18,12 +18,19 @@ type EntityPoint struct {
 	Entity Entity
 }
 
+// maxDepth stops subdivision; without it entities sharing one position,
+// e.g. animals pressed into a corner, would split the tree forever.
+const maxDepth = 20
+
 type QuadTree struct {
 	capacity int
 	boundary Rectangle
 	points []EntityPoint
 	nw,ne,sw,se *QuadTree
 	divided bool
+	depth int
+	// count is the number of points in this node and all its children.
+	count int
 }
 
 func NewQuadTree(capacity int, rect Rectangle) *QuadTree {
@@ -45,14 +52,12 @@ func (qt *QuadTree) subdivide() {
 	qt.nw = NewQuadTree(qt.capacity, geom.Rectangle{X: x, Y: y+h, Width: w, Height: h})
 	qt.se = NewQuadTree(qt.capacity, geom.Rectangle{X: x+w, Y: y, Width: w, Height: h})
 	qt.sw = NewQuadTree(qt.capacity, geom.Rectangle{X: x, Y: y, Width: w, Height: h})
+	qt.ne.depth, qt.nw.depth, qt.se.depth, qt.sw.depth = qt.depth+1, qt.depth+1, qt.depth+1, qt.depth+1
 
 	qt.divided = true
 
 	for _, ep := range qt.points {
-		_ = qt.ne.Insert(ep.Point, ep.Entity) ||
-		qt.nw.Insert(ep.Point, ep.Entity) ||
-		qt.se.Insert(ep.Point, ep.Entity) ||
-		qt.sw.Insert(ep.Point, ep.Entity)
+		qt.childFor(ep.Point).Insert(ep.Point, ep.Entity)
 	}
 
 	qt.points = nil
@@ -62,6 +67,12 @@ func (qt *QuadTree) Clear() {
 	qt.points = qt.points[:0]
 	qt.divided = false
 	qt.ne, qt.nw, qt.se, qt.sw = nil, nil, nil, nil
+	qt.count = 0
+}
+
+// Len returns the number of indexed entities.
+func (qt *QuadTree) Len() int {
+	return qt.count
 }
 
 func (qt *QuadTree) Insert(point Point, entity Entity) bool {
@@ -72,16 +83,115 @@ func (qt *QuadTree) Insert(point Point, entity Entity) bool {
 	entityPoint := EntityPoint{Point: point, Entity: entity}
 
 	if !qt.divided {
-		if len(qt.points) < qt.capacity {
+		if len(qt.points) < qt.capacity || qt.depth >= maxDepth {
 			qt.points = append(qt.points, entityPoint)
+			qt.count++
 			return true
 		}
 		qt.subdivide()
 	}
-	return qt.ne.Insert(point, entity) ||
-		qt.nw.Insert(point, entity) ||
-		qt.se.Insert(point, entity) ||
-		qt.sw.Insert(point, entity)
+	if qt.childFor(point).Insert(point, entity) {
+		qt.count++
+		return true
+	}
+	return false
+}
+
+// childFor returns the child a point belongs to. Points on a shared edge
+// go to the first child in Insert's order, so lookups find them again.
+func (qt *QuadTree) childFor(point Point) *QuadTree {
+	for _, child := range []*QuadTree{qt.ne, qt.nw, qt.se, qt.sw} {
+		if child.boundary.Contains(point) {
+			return child
+		}
+	}
+	return nil
+}
+
+// Remove deletes entity, indexed at point, and merges subtrees that have
+// become underfull. It reports whether the entity was found.
+func (qt *QuadTree) Remove(point Point, entity Entity) bool {
+	if !qt.boundary.Contains(point) {
+		return false
+	}
+
+	if !qt.divided {
+		for i, ep := range qt.points {
+			if ep.Entity == entity {
+				last := len(qt.points) - 1
+				qt.points[i] = qt.points[last]
+				qt.points[last] = EntityPoint{}
+				qt.points = qt.points[:last]
+				qt.count--
+				return true
+			}
+		}
+		return false
+	}
+
+	if !qt.childFor(point).Remove(point, entity) {
+		return false
+	}
+	qt.count--
+	qt.collapse()
+	return true
+}
+
+// Move updates the position of entity from one point to another. The
+// entry is updated in place while it stays in the same leaf and migrates
+// to the right node otherwise. It reports whether the entity was found
+// and the new point lies inside the tree.
+func (qt *QuadTree) Move(from, to Point, entity Entity) bool {
+	if !qt.boundary.Contains(from) || !qt.boundary.Contains(to) {
+		return false
+	}
+
+	if !qt.divided {
+		for i := range qt.points {
+			if qt.points[i].Entity == entity {
+				qt.points[i].Point = to
+				return true
+			}
+		}
+		return false
+	}
+
+	source, target := qt.childFor(from), qt.childFor(to)
+	if source == target {
+		return source.Move(from, to, entity)
+	}
+
+	if !source.Remove(from, entity) {
+		return false
+	}
+	target.Insert(to, entity)
+	return true
+}
+
+// collapse turns a divided node back into a leaf once its subtree holds
+// at most half its capacity, so the tree shrinks as entities die.
+func (qt *QuadTree) collapse() {
+	if !qt.divided || qt.count > qt.capacity/2 {
+		return
+	}
+
+	points := make([]EntityPoint, 0, qt.capacity)
+	qt.collect(&points)
+
+	qt.points = points
+	qt.divided = false
+	qt.ne, qt.nw, qt.se, qt.sw = nil, nil, nil, nil
+}
+
+func (qt *QuadTree) collect(points *[]EntityPoint) {
+	if !qt.divided {
+		*points = append(*points, qt.points...)
+		return
+	}
+	qt.ne.collect(points)
+	qt.nw.collect(points)
+	qt.se.collect(points)
+	qt.sw.collect(points)
 }
 
 func (qt *QuadTree) Query(rangeRect Rectangle, found *[]Entity) {
@@ -0,0 +1,323 @@
+package quadtree
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// testEntity is the least an entity needs to be indexed.
+type testEntity struct {
+	id      uint64
+	species string
+	energy  float64
+	dead    bool
+}
+
+func (e *testEntity) Decide(interfaces.WorldInterface) interfaces.Action { return interfaces.Action{} }
+func (e *testEntity) Act(interfaces.WorldInterface, interfaces.Action)   {}
+func (e *testEntity) GetID() uint64                                      { return e.id }
+func (e *testEntity) SetID(id uint64)                                    { e.id = id }
+func (e *testEntity) GetPosition() geom.Point                            { return geom.Point{} }
+func (e *testEntity) GetSpecies() string                                 { return e.species }
+func (e *testEntity) GetDiet() []string                                  { return nil }
+func (e *testEntity) IsAlive() bool                                      { return !e.dead }
+func (e *testEntity) GetEnergy() float64                                 { return e.energy }
+func (e *testEntity) UpdateEnergy(amount float64)                        { e.energy += amount }
+func (e *testEntity) Kill(interfaces.Cause)                              { e.dead = true }
+func (e *testEntity) GetDeathCause() interfaces.Cause                    { return "" }
+
+var board = Rectangle{X: 0, Y: 0, Width: 800, Height: 400}
+
+// scatter inserts n entities at random points and returns where each is.
+func scatter(qt *QuadTree, n int, rng *rand.Rand) map[Entity]Point {
+	at := make(map[Entity]Point, n)
+	for i := 0; i < n; i++ {
+		e := &testEntity{id: uint64(i + 1), species: "rabbit", energy: 1}
+		p := randomPoint(rng)
+		qt.Insert(p, e)
+		at[e] = p
+	}
+	return at
+}
+
+func randomPoint(rng *rand.Rand) Point {
+	return Point{X: rng.Float64() * board.Width, Y: rng.Float64() * board.Height}
+}
+
+// checkTree verifies that the tree holds exactly the entities in at, each
+// at its point, and that every node's count matches its subtree.
+func checkTree(t *testing.T, qt *QuadTree, at map[Entity]Point) {
+	t.Helper()
+	if qt.Len() != len(at) {
+		t.Fatalf("Len() = %d, want %d", qt.Len(), len(at))
+	}
+	var all []Entity
+	qt.Query(board, &all)
+	if len(all) != len(at) {
+		t.Fatalf("Query found %d entities, want %d", len(all), len(at))
+	}
+	for e, p := range at {
+		var found []Entity
+		qt.Query(Rectangle{X: p.X, Y: p.Y}, &found)
+		ok := false
+		for _, f := range found {
+			ok = ok || f == e
+		}
+		if !ok {
+			t.Fatalf("entity %d is not found at %v", e.GetID(), p)
+		}
+	}
+	checkCounts(t, qt)
+}
+
+func checkCounts(t *testing.T, qt *QuadTree) int {
+	t.Helper()
+	if !qt.divided {
+		for _, ep := range qt.points {
+			if !qt.boundary.Contains(ep.Point) {
+				t.Fatalf("point %v lies outside its node %v", ep.Point, qt.boundary)
+			}
+		}
+		if qt.count != len(qt.points) {
+			t.Fatalf("leaf count %d, holds %d points", qt.count, len(qt.points))
+		}
+		return qt.count
+	}
+	if len(qt.points) != 0 {
+		t.Fatalf("divided node holds %d points", len(qt.points))
+	}
+	n := checkCounts(t, qt.ne) + checkCounts(t, qt.nw) + checkCounts(t, qt.se) + checkCounts(t, qt.sw)
+	if qt.count != n {
+		t.Fatalf("node count %d, subtree holds %d", qt.count, n)
+	}
+	return n
+}
+
+func TestInsertOutside(t *testing.T) {
+	qt := NewQuadTree(4, board)
+	if qt.Insert(Point{X: -1, Y: 10}, &testEntity{id: 1}) {
+		t.Fatal("Insert accepted a point outside the tree")
+	}
+	if qt.Len() != 0 {
+		t.Fatalf("Len() = %d after a rejected insert", qt.Len())
+	}
+}
+
+func TestRemove(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	qt := NewQuadTree(4, board)
+	at := scatter(qt, 500, rng)
+
+	stranger := &testEntity{id: 9999}
+	if qt.Remove(Point{X: 10, Y: 10}, stranger) {
+		t.Fatal("Remove found an entity that was never inserted")
+	}
+	for e, p := range at {
+		if e.GetID()%2 == 0 {
+			continue
+		}
+		if qt.Remove(Point{X: board.Width - p.X, Y: board.Height - p.Y}, e) {
+			t.Fatalf("Remove found entity %d at the wrong point", e.GetID())
+		}
+		if !qt.Remove(p, e) {
+			t.Fatalf("Remove did not find entity %d at %v", e.GetID(), p)
+		}
+		if qt.Remove(p, e) {
+			t.Fatalf("entity %d was removed twice", e.GetID())
+		}
+		delete(at, e)
+	}
+	checkTree(t, qt, at)
+}
+
+func TestRemoveSharedPoint(t *testing.T) {
+	qt := NewQuadTree(2, board)
+	p := Point{X: 0, Y: 0}
+	at := make(map[Entity]Point)
+	for i := 0; i < 50; i++ {
+		e := &testEntity{id: uint64(i + 1)}
+		qt.Insert(p, e)
+		at[e] = p
+	}
+	checkTree(t, qt, at)
+	for e := range at {
+		if !qt.Remove(p, e) {
+			t.Fatalf("Remove did not find entity %d", e.GetID())
+		}
+		delete(at, e)
+		checkTree(t, qt, at)
+	}
+}
+
+func TestMove(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	qt := NewQuadTree(4, board)
+	at := scatter(qt, 500, rng)
+
+	for round := 0; round < 20; round++ {
+		for e, from := range at {
+			to := from
+			if round%2 == 0 {
+				// Short steps mostly stay in their leaf.
+				to.X = clampTo(from.X+rng.Float64()*6-3, board.Width)
+				to.Y = clampTo(from.Y+rng.Float64()*6-3, board.Height)
+			} else {
+				to = randomPoint(rng)
+			}
+			if !qt.Move(from, to, e) {
+				t.Fatalf("Move did not find entity %d at %v", e.GetID(), from)
+			}
+			at[e] = to
+		}
+		checkTree(t, qt, at)
+	}
+
+	for e, p := range at {
+		if qt.Move(p, Point{X: board.Width + 1, Y: p.Y}, e) {
+			t.Fatal("Move accepted a point outside the tree")
+		}
+		if qt.Move(Point{X: board.Width - p.X, Y: board.Height - p.Y}, p, e) {
+			t.Fatalf("Move found entity %d at the wrong point", e.GetID())
+		}
+		break
+	}
+	if qt.Move(Point{X: 1, Y: 1}, Point{X: 2, Y: 2}, &testEntity{id: 9999}) {
+		t.Fatal("Move found an entity that was never inserted")
+	}
+	checkTree(t, qt, at)
+}
+
+func clampTo(v, size float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > size {
+		return size
+	}
+	return v
+}
+
+func TestCollapse(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	qt := NewQuadTree(8, board)
+	at := scatter(qt, 300, rng)
+	if !qt.divided {
+		t.Fatal("300 entities did not divide a tree of capacity 8")
+	}
+
+	for e, p := range at {
+		if len(at) <= qt.capacity/2 {
+			break
+		}
+		qt.Remove(p, e)
+		delete(at, e)
+	}
+	if qt.divided {
+		t.Fatalf("root is still divided holding %d entities", qt.Len())
+	}
+	checkTree(t, qt, at)
+
+	for e, p := range at {
+		qt.Remove(p, e)
+		delete(at, e)
+	}
+	checkTree(t, qt, at)
+	if qt.divided || len(qt.points) != 0 {
+		t.Fatal("an emptied tree is not an empty leaf")
+	}
+}
+
+// TestCollapseSubtree checks that a subtree shrinks back to a leaf while
+// the rest of the tree stays divided.
+func TestCollapseSubtree(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 4))
+	qt := NewQuadTree(4, board)
+	at := make(map[Entity]Point)
+	for i := 0; i < 200; i++ {
+		e := &testEntity{id: uint64(i + 1)}
+		// All in the south-west quarter.
+		p := Point{X: rng.Float64() * board.Width / 2, Y: rng.Float64() * board.Height / 2}
+		qt.Insert(p, e)
+		at[e] = p
+	}
+	for i := 0; i < 8; i++ {
+		e := &testEntity{id: uint64(1000 + i)}
+		p := Point{X: board.Width*0.75 + float64(i), Y: board.Height * 0.75}
+		qt.Insert(p, e)
+		at[e] = p
+	}
+	if !qt.sw.divided {
+		t.Fatal("the crowded quarter is not divided")
+	}
+
+	for e, p := range at {
+		if e.GetID() < 1000 && qt.sw.count > 2 {
+			qt.Remove(p, e)
+			delete(at, e)
+		}
+	}
+	if qt.sw.divided {
+		t.Fatalf("the quarter is still divided holding %d entities", qt.sw.count)
+	}
+	if !qt.divided {
+		t.Fatal("the root collapsed while holding more than half its capacity")
+	}
+	checkTree(t, qt, at)
+}
+
+// The benchmarks move every entity a short step per tick, once by
+// rebuilding the tree from scratch as the world used to and once by
+// moving the entities in place.
+const benchEntities = 20000
+
+func benchSetup() ([]Entity, []Point, *rand.Rand) {
+	rng := rand.New(rand.NewPCG(5, 5))
+	entities := make([]Entity, benchEntities)
+	points := make([]Point, benchEntities)
+	for i := range entities {
+		entities[i] = &testEntity{id: uint64(i + 1)}
+		points[i] = randomPoint(rng)
+	}
+	return entities, points, rng
+}
+
+func step(p Point, rng *rand.Rand) Point {
+	return Point{X: clampTo(p.X+rng.Float64()*4-2, board.Width), Y: clampTo(p.Y+rng.Float64()*4-2, board.Height)}
+}
+
+func BenchmarkRebuild(b *testing.B) {
+	entities, points, rng := benchSetup()
+	qt := NewQuadTree(10, board)
+	for i, e := range entities {
+		qt.Insert(points[i], e)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := range points {
+			points[i] = step(points[i], rng)
+		}
+		qt.Clear()
+		for i, e := range entities {
+			qt.Insert(points[i], e)
+		}
+	}
+}
+
+func BenchmarkMove(b *testing.B) {
+	entities, points, rng := benchSetup()
+	qt := NewQuadTree(10, board)
+	for i, e := range entities {
+		qt.Insert(points[i], e)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i, e := range entities {
+			to := step(points[i], rng)
+			qt.Move(points[i], to, e)
+			points[i] = to
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package terrain holds the ground under the board: a grid of tiles, each
+// meadow, forest, water or rock, loaded from a map file or generated from
+// noise.
+package terrain
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+type Kind uint8
+
+const (
+	Meadow Kind = iota
+	Forest
+	Water
+	Rock
+)
+
+var names = [...]string{"meadow", "forest", "water", "rock"}
+
+func (k Kind) String() string {
+	if int(k) < len(names) {
+		return names[k]
+	}
+	return fmt.Sprintf("Kind(%d)", k)
+}
+
+// Params returns the settings for k from the config.
+func (k Kind) Params(types config.TerrainTypes) config.TerrainType {
+	switch k {
+	case Forest:
+		return types.Forest
+	case Water:
+		return types.Water
+	case Rock:
+		return types.Rock
+	default:
+		return types.Meadow
+	}
+}
+
+// Color is how the GUI draws k; dark enough for entities to stand out.
+func (k Kind) Color() color.RGBA {
+	switch k {
+	case Forest:
+		return color.RGBA{10, 40, 20, 255}
+	case Water:
+		return color.RGBA{15, 35, 90, 255}
+	case Rock:
+		return color.RGBA{70, 70, 70, 255}
+	default:
+		return color.RGBA{25, 25, 15, 255}
+	}
+}
+
+// Map is a grid of tiles stretched over the whole board.
+type Map struct {
+	Cols  int    `json:"cols"`
+	Rows  int    `json:"rows"`
+	Tiles []Kind `json:"tiles"`
+}
+
+func New(cols, rows int) *Map {
+	return &Map{Cols: cols, Rows: rows, Tiles: make([]Kind, cols*rows)}
+}
+
+// At returns the tile at (u, v), both given as fractions of the board
+// size in [0, 1).
+func (m *Map) At(u, v float64) Kind {
+	col := min(max(int(u*float64(m.Cols)), 0), m.Cols-1)
+	row := min(max(int(v*float64(m.Rows)), 0), m.Rows-1)
+	return m.Tiles[row*m.Cols+col]
+}
+
+// Check reports a map whose tiles do not match its size.
+func (m *Map) Check() error {
+	if m.Cols <= 0 || m.Rows <= 0 {
+		return fmt.Errorf("terrain: invalid size %dx%d", m.Cols, m.Rows)
+	}
+	if len(m.Tiles) != m.Cols*m.Rows {
+		return fmt.Errorf("terrain: %d tiles, want %d", len(m.Tiles), m.Cols*m.Rows)
+	}
+	for i, k := range m.Tiles {
+		if int(k) >= len(names) {
+			return fmt.Errorf("terrain: tile %d has unknown kind %d", i, k)
+		}
+	}
+	return nil
+}
+
+// FromConfig builds the terrain cfg asks for, or returns nil for a
+// uniform board. Generated terrain depends only on seed and the board
+// size, so equal seeds give equal maps.
+func FromConfig(cfg config.Terrain, width, height int, seed uint64) (*Map, error) {
+	switch cfg.Map {
+	case "":
+		return nil, nil
+	case config.TerrainNoise:
+		return Generate(width, height, cfg.NoiseScale, seed), nil
+	default:
+		return Load(cfg.Map)
+	}
+}
+
+// Load reads a .png map, or an ASCII map for any other extension.
+func Load(path string) (*Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("terrain: %w", err)
+	}
+	defer f.Close()
+
+	var m *Map
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		m, err = DecodePNG(f)
+	} else {
+		m, err = DecodeASCII(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("terrain %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ASCII maps use one character per tile: '.' meadow, 'T' forest,
+// '~' water and '#' rock. Short lines are padded with meadow.
+var asciiKinds = map[rune]Kind{'.': Meadow, 'T': Forest, '~': Water, '#': Rock}
+
+func DecodeASCII(r io.Reader) (*Map, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	cols := 0
+	for _, line := range lines {
+		cols = max(cols, len([]rune(line)))
+	}
+	if cols == 0 {
+		return nil, fmt.Errorf("empty map")
+	}
+
+	m := New(cols, len(lines))
+	for row, line := range lines {
+		for col, c := range []rune(line) {
+			kind, ok := asciiKinds[c]
+			if !ok {
+				return nil, fmt.Errorf("line %d, column %d: unknown tile %q", row+1, col+1, c)
+			}
+			m.Tiles[row*cols+col] = kind
+		}
+	}
+	return m, nil
+}
+
+// PNG maps are matched pixel by pixel to the closest of these colours.
+var pngColors = [...]color.RGBA{
+	Meadow: {120, 200, 80, 255},
+	Forest: {20, 100, 30, 255},
+	Water:  {40, 80, 220, 255},
+	Rock:   {128, 128, 128, 255},
+}
+
+func DecodePNG(r io.Reader) (*Map, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return fromImage(img), nil
+}
+
+// fromImage makes a tile of every pixel of img, its top left corner
+// becoming the first tile wherever the image's bounds start.
+func fromImage(img image.Image) *Map {
+	bounds := img.Bounds()
+	m := New(bounds.Dx(), bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			m.Tiles[(y-bounds.Min.Y)*m.Cols+(x-bounds.Min.X)] = closest(img, x, y)
+		}
+	}
+	return m
+}
+
+func closest(img image.Image, x, y int) Kind {
+	c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	best, bestDistance := Meadow, math.Inf(1)
+	for k, ref := range pngColors {
+		dr := float64(c.R) - float64(ref.R)
+		dg := float64(c.G) - float64(ref.G)
+		db := float64(c.B) - float64(ref.B)
+		if d := dr*dr + dg*dg + db*db; d < bestDistance {
+			best, bestDistance = Kind(k), d
+		}
+	}
+	return best
+}
+
+// tileSize is the side of a generated tile in board units.
+const tileSize = 2.0
+
+// Generate builds terrain from two layers of Perlin noise: elevation
+// puts water in the lows and rock on the peaks, moisture splits the rest
+// between forest and meadow.
+func Generate(width, height int, scale float64, seed uint64) *Map {
+	cols := max(int(math.Ceil(float64(width)/tileSize)), 1)
+	rows := max(int(math.Ceil(float64(height)/tileSize)), 1)
+	m := New(cols, rows)
+
+	// A stream of its own keeps the world's random draws unchanged.
+	rng := rand.New(rand.NewPCG(seed, 0x7e44a1))
+	elevation := newPerlin(rng)
+	moisture := newPerlin(rng)
+
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			x := (float64(col) + 0.5) * tileSize / scale
+			y := (float64(row) + 0.5) * tileSize / scale
+
+			kind := Meadow
+			switch e := elevation.fractal(x, y); {
+			case e < -0.25:
+				kind = Water
+			case e > 0.35:
+				kind = Rock
+			case moisture.fractal(x, y) > 0.1:
+				kind = Forest
+			}
+			m.Tiles[row*cols+col] = kind
+		}
+	}
+	return m
+}
+
+type perlin struct {
+	perm [512]uint8
+}
+
+func newPerlin(rng *rand.Rand) *perlin {
+	p := &perlin{}
+	for i, v := range rng.Perm(256) {
+		p.perm[i] = uint8(v)
+		p.perm[i+256] = uint8(v)
+	}
+	return p
+}
+
+// fractal sums three octaves of noise; the result is roughly in [-1, 1].
+func (p *perlin) fractal(x, y float64) float64 {
+	sum, amplitude, norm := 0.0, 1.0, 0.0
+	for octave := 0; octave < 3; octave++ {
+		sum += amplitude * p.noise(x, y)
+		norm += amplitude
+		amplitude /= 2
+		x, y = x*2, y*2
+	}
+	return sum / norm
+}
+
+func (p *perlin) noise(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	xi, yi := int(x0)&255, int(y0)&255
+	xf, yf := x-x0, y-y0
+	u, v := fade(xf), fade(yf)
+
+	aa := p.perm[int(p.perm[xi])+yi]
+	ab := p.perm[int(p.perm[xi])+yi+1]
+	ba := p.perm[int(p.perm[xi+1])+yi]
+	bb := p.perm[int(p.perm[xi+1])+yi+1]
+
+	bottom := lerp(grad(aa, xf, yf), grad(ba, xf-1, yf), u)
+	top := lerp(grad(ab, xf, yf-1), grad(bb, xf-1, yf-1), u)
+	return lerp(bottom, top, v)
+}
+
+func fade(t float64) float64 { return t * t * t * (t*(t*6-15) + 10) }
+
+func lerp(a, b, t float64) float64 { return a + t*(b-a) }
+
+func grad(hash uint8, x, y float64) float64 {
+	switch hash & 3 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	default:
+		return -x - y
+	}
+}
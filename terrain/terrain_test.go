@@ -0,0 +1,202 @@
+package terrain
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeASCII(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		cols, rows int
+		tiles      []Kind
+	}{
+		{"full", ".T\n~#\n", 2, 2, []Kind{Meadow, Forest, Water, Rock}},
+		{"no final newline", ".T\n~#", 2, 2, []Kind{Meadow, Forest, Water, Rock}},
+		{"short lines padded with meadow", "#\n~~~\n", 3, 2, []Kind{Rock, Meadow, Meadow, Water, Water, Water}},
+		{"blank line inside kept", "#\n\n#\n", 1, 3, []Kind{Rock, Meadow, Rock}},
+		{"trailing blank lines trimmed", "T#\n\n\n", 2, 1, []Kind{Forest, Rock}},
+		{"CRLF", "T#\r\n~.\r\n\r\n", 2, 2, []Kind{Forest, Rock, Water, Meadow}},
+	}
+	for _, tt := range tests {
+		m, err := DecodeASCII(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if m.Cols != tt.cols || m.Rows != tt.rows || !slices.Equal(m.Tiles, tt.tiles) {
+			t.Errorf("%s: got %dx%d %v, want %dx%d %v", tt.name, m.Cols, m.Rows, m.Tiles, tt.cols, tt.rows, tt.tiles)
+		}
+		if err := m.Check(); err != nil {
+			t.Errorf("%s: decoded map fails its check: %v", tt.name, err)
+		}
+	}
+}
+
+func TestDecodeASCIIErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "empty map"},
+		{"only blank lines", "\n\r\n\n", "empty map"},
+		{"unknown tile", "..\n.x#\n", `line 2, column 2: unknown tile 'x'`},
+		{"unknown tile after CRLF", "~~\r\n~~\r\n~~?\r\n", `line 3, column 3: unknown tile '?'`},
+	}
+	for _, tt := range tests {
+		_, err := DecodeASCII(strings.NewReader(tt.in))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	tests := []struct {
+		c    color.Color
+		want Kind
+	}{
+		{pngColors[Meadow], Meadow},
+		{pngColors[Forest], Forest},
+		{pngColors[Water], Water},
+		{pngColors[Rock], Rock},
+		{color.RGBA{110, 210, 90, 255}, Meadow},
+		{color.RGBA{0, 80, 0, 255}, Forest},
+		{color.RGBA{0, 0, 255, 255}, Water},
+		{color.RGBA{255, 255, 255, 255}, Rock},
+		{color.Gray{100}, Rock},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		img.Set(0, 0, tt.c)
+		if got := closest(img, 0, 0); got != tt.want {
+			t.Errorf("closest(%v) = %v, want %v", tt.c, got, tt.want)
+		}
+	}
+}
+
+// paint fills img row by row with the reference colours of kinds.
+func paint(img *image.RGBA, kinds []Kind) {
+	b := img.Bounds()
+	for i, k := range kinds {
+		img.Set(b.Min.X+i%b.Dx(), b.Min.Y+i/b.Dx(), pngColors[k])
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	kinds := []Kind{Meadow, Forest, Water, Rock, Rock, Water}
+	tests := []struct {
+		name   string
+		bounds image.Rectangle
+	}{
+		{"origin", image.Rect(0, 0, 3, 2)},
+		{"offset", image.Rect(5, 7, 8, 9)},
+		{"negative", image.Rect(-2, -1, 1, 1)},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(tt.bounds)
+		paint(img, kinds)
+		m := fromImage(img)
+		if m.Cols != 3 || m.Rows != 2 || !slices.Equal(m.Tiles, kinds) {
+			t.Errorf("%s: got %dx%d %v, want 3x2 %v", tt.name, m.Cols, m.Rows, m.Tiles, kinds)
+		}
+	}
+
+	// A sub-image keeps the bounds of the part cut out of its parent.
+	parent := image.NewRGBA(image.Rect(0, 0, 6, 5))
+	sub := parent.SubImage(image.Rect(2, 3, 5, 5)).(*image.RGBA)
+	paint(sub, kinds)
+	if m := fromImage(sub); !slices.Equal(m.Tiles, kinds) {
+		t.Errorf("sub-image: got %v, want %v", m.Tiles, kinds)
+	}
+}
+
+func TestDecodePNG(t *testing.T) {
+	kinds := []Kind{Water, Meadow, Rock, Forest}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	paint(img, kinds)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	m, err := DecodePNG(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Cols != 2 || m.Rows != 2 || !slices.Equal(m.Tiles, kinds) {
+		t.Errorf("got %dx%d %v, want 2x2 %v", m.Cols, m.Rows, m.Tiles, kinds)
+	}
+
+	if _, err := DecodePNG(strings.NewReader("#~\n")); err == nil {
+		t.Error("an ASCII map decoded as a PNG")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		m    *Map
+		ok   bool
+	}{
+		{"valid", New(2, 3), true},
+		{"zero size", &Map{}, false},
+		{"negative size", &Map{Cols: -1, Rows: 2}, false},
+		{"too few tiles", &Map{Cols: 2, Rows: 2, Tiles: make([]Kind, 3)}, false},
+		{"too many tiles", &Map{Cols: 1, Rows: 1, Tiles: make([]Kind, 2)}, false},
+		{"unknown kind", &Map{Cols: 2, Rows: 1, Tiles: []Kind{Meadow, Rock + 1}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.m.Check(); (err == nil) != tt.ok {
+			t.Errorf("%s: Check() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	paint(img, []Kind{Rock, Water})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"map.png": buf.Bytes(),
+		"map.PNG": buf.Bytes(),
+		"map.txt": []byte("#~\n"),
+		"map":     []byte("#~\n"),
+		// PNG data under another extension is read as ASCII and rejected.
+		"png.txt": buf.Bytes(),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"map.png", "map.PNG", "map.txt", "map"} {
+		m, err := Load(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if want := []Kind{Rock, Water}; m.Cols != 2 || m.Rows != 1 || !slices.Equal(m.Tiles, want) {
+			t.Errorf("%s: got %dx%d %v, want 2x1 %v", name, m.Cols, m.Rows, m.Tiles, want)
+		}
+	}
+	for _, name := range []string{"png.txt", "missing.png"} {
+		if _, err := Load(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: loaded without an error", name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package world
+
+import (
+	"math"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+)
+
+// Confine maps p back onto the board according to the boundary policy.
+// The result always lies inside the quadtree's boundary.
+func (w *World) Confine(p geom.Point) geom.Point {
+	width, height := float64(w.Width), float64(w.Height)
+
+	switch w.Config.World.Boundary {
+	case config.BoundaryTorus:
+		p.X = wrap(p.X, width)
+		p.Y = wrap(p.Y, height)
+	case config.BoundaryReflect:
+		p.X = reflect(p.X, width)
+		p.Y = reflect(p.Y, height)
+	default:
+		p.X = clamp(p.X, width)
+		p.Y = clamp(p.Y, height)
+	}
+	return p
+}
+
+// Offset returns the displacement from one point to another. On a torus
+// it is the shortest one, which may cross an edge.
+func (w *World) Offset(from, to geom.Point) (dx, dy float64) {
+	dx = to.X - from.X
+	dy = to.Y - from.Y
+
+	if w.Config.World.Boundary == config.BoundaryTorus {
+		dx = shortest(dx, float64(w.Width))
+		dy = shortest(dy, float64(w.Height))
+	}
+	return dx, dy
+}
+
+// SearchCenters returns the points to search around for everything
+// within radius of pos. On a torus a search near an edge is repeated
+// from pos shifted by the board size, so it also reaches the
+// wrapped-around part on the opposite side and measures true distances.
+// Drawing a circle around each of them shows it wrapped the same way.
+func (w *World) SearchCenters(pos geom.Point, radius float64) []geom.Point {
+	if w.Config.World.Boundary != config.BoundaryTorus {
+		return []geom.Point{pos}
+	}
+
+	width, height := float64(w.Width), float64(w.Height)
+	xShifts := []float64{0}
+	if pos.X-radius < 0 {
+		xShifts = append(xShifts, width)
+	}
+	if pos.X+radius > width {
+		xShifts = append(xShifts, -width)
+	}
+	yShifts := []float64{0}
+	if pos.Y-radius < 0 {
+		yShifts = append(yShifts, height)
+	}
+	if pos.Y+radius > height {
+		yShifts = append(yShifts, -height)
+	}
+
+	centers := make([]geom.Point, 0, len(xShifts)*len(yShifts))
+	for _, sx := range xShifts {
+		for _, sy := range yShifts {
+			centers = append(centers, geom.Point{X: pos.X + sx, Y: pos.Y + sy})
+		}
+	}
+	return centers
+}
+
+// wrap maps v into [0, size).
+func wrap(v, size float64) float64 {
+	v = math.Mod(v, size)
+	if v < 0 {
+		v += size
+	}
+	if v >= size {
+		v = 0
+	}
+	return v
+}
+
+// reflect folds v back into [0, size) as if the edges were mirrors.
+func reflect(v, size float64) float64 {
+	period := 2 * size
+	v = math.Mod(v, period)
+	if v < 0 {
+		v += period
+	}
+	if v >= size {
+		v = period - v
+	}
+	return clamp(v, size)
+}
+
+// clamp limits v to [0, size).
+func clamp(v, size float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v >= size {
+		return math.Nextafter(size, 0)
+	}
+	return v
+}
+
+func shortest(d, size float64) float64 {
+	if d > size/2 {
+		return d - size
+	}
+	if d < -size/2 {
+		return d + size
+	}
+	return d
+}
@@ -0,0 +1,171 @@
+package world
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+)
+
+// boundaryWorld is an empty 100x50 world with the given boundary.
+func boundaryWorld(boundary string) *World {
+	cfg := config.Default()
+	cfg.World.Width, cfg.World.Height = 100, 50
+	cfg.World.Boundary = boundary
+	return NewWorld(cfg, 1)
+}
+
+func TestConfine(t *testing.T) {
+	below := math.Nextafter(100, 0)
+	tests := []struct {
+		boundary string
+		in, want geom.Point
+	}{
+		{config.BoundaryClamp, geom.Point{X: 40, Y: 20}, geom.Point{X: 40, Y: 20}},
+		{config.BoundaryClamp, geom.Point{X: -5, Y: 60}, geom.Point{X: 0, Y: math.Nextafter(50, 0)}},
+		{config.BoundaryClamp, geom.Point{X: 100, Y: -0.5}, geom.Point{X: below, Y: 0}},
+		{config.BoundaryClamp, geom.Point{X: 1e6, Y: -1e6}, geom.Point{X: below, Y: 0}},
+
+		{config.BoundaryTorus, geom.Point{X: 40, Y: 20}, geom.Point{X: 40, Y: 20}},
+		{config.BoundaryTorus, geom.Point{X: -5, Y: 55}, geom.Point{X: 95, Y: 5}},
+		{config.BoundaryTorus, geom.Point{X: 100, Y: 50}, geom.Point{X: 0, Y: 0}},
+		{config.BoundaryTorus, geom.Point{X: 350, Y: -120}, geom.Point{X: 50, Y: 30}},
+		// A tiny negative value wraps to the far edge, which is rounded to 0.
+		{config.BoundaryTorus, geom.Point{X: -1e-18, Y: 25}, geom.Point{X: 0, Y: 25}},
+
+		{config.BoundaryReflect, geom.Point{X: 40, Y: 20}, geom.Point{X: 40, Y: 20}},
+		{config.BoundaryReflect, geom.Point{X: -5, Y: 55}, geom.Point{X: 5, Y: 45}},
+		{config.BoundaryReflect, geom.Point{X: 100, Y: 0}, geom.Point{X: below, Y: 0}},
+		// Overshoots bigger than the board bounce off both edges.
+		{config.BoundaryReflect, geom.Point{X: 250, Y: -130}, geom.Point{X: 50, Y: 30}},
+		{config.BoundaryReflect, geom.Point{X: -250, Y: 170}, geom.Point{X: 50, Y: 30}},
+		{config.BoundaryReflect, geom.Point{X: 200, Y: 100}, geom.Point{X: 0, Y: 0}},
+	}
+	for _, tt := range tests {
+		w := boundaryWorld(tt.boundary)
+		got := w.Confine(tt.in)
+		if math.Abs(got.X-tt.want.X) > 1e-9 || math.Abs(got.Y-tt.want.Y) > 1e-9 {
+			t.Errorf("%s: Confine(%v) = %v, want %v", tt.boundary, tt.in, got, tt.want)
+		}
+		if got.X < 0 || got.X >= 100 || got.Y < 0 || got.Y >= 50 {
+			t.Errorf("%s: Confine(%v) = %v is off the board", tt.boundary, tt.in, got)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		boundary       string
+		from, to       geom.Point
+		wantDX, wantDY float64
+	}{
+		{config.BoundaryClamp, geom.Point{X: 1, Y: 1}, geom.Point{X: 99, Y: 49}, 98, 48},
+		{config.BoundaryReflect, geom.Point{X: 1, Y: 1}, geom.Point{X: 99, Y: 49}, 98, 48},
+		{config.BoundaryTorus, geom.Point{X: 1, Y: 1}, geom.Point{X: 99, Y: 49}, -2, -2},
+		{config.BoundaryTorus, geom.Point{X: 99, Y: 49}, geom.Point{X: 1, Y: 1}, 2, 2},
+		{config.BoundaryTorus, geom.Point{X: 10, Y: 10}, geom.Point{X: 40, Y: 30}, 30, 20},
+		{config.BoundaryTorus, geom.Point{X: 10, Y: 10}, geom.Point{X: 70, Y: 40}, -40, -20},
+	}
+	for _, tt := range tests {
+		w := boundaryWorld(tt.boundary)
+		if dx, dy := w.Offset(tt.from, tt.to); dx != tt.wantDX || dy != tt.wantDY {
+			t.Errorf("%s: Offset(%v, %v) = %g, %g, want %g, %g", tt.boundary, tt.from, tt.to, dx, dy, tt.wantDX, tt.wantDY)
+		}
+	}
+}
+
+func TestSearchCenters(t *testing.T) {
+	tests := []struct {
+		name     string
+		boundary string
+		pos      geom.Point
+		radius   float64
+		want     []geom.Point
+	}{
+		{"clamp corner", config.BoundaryClamp, geom.Point{X: 2, Y: 3}, 5, []geom.Point{{X: 2, Y: 3}}},
+		{"torus middle", config.BoundaryTorus, geom.Point{X: 50, Y: 25}, 5, []geom.Point{{X: 50, Y: 25}}},
+		{"torus left edge", config.BoundaryTorus, geom.Point{X: 2, Y: 25}, 5,
+			[]geom.Point{{X: 2, Y: 25}, {X: 102, Y: 25}}},
+		{"torus bottom edge", config.BoundaryTorus, geom.Point{X: 50, Y: 48}, 5,
+			[]geom.Point{{X: 50, Y: 48}, {X: 50, Y: -2}}},
+		{"torus corner", config.BoundaryTorus, geom.Point{X: 2, Y: 3}, 5,
+			[]geom.Point{{X: 2, Y: 3}, {X: 2, Y: 53}, {X: 102, Y: 3}, {X: 102, Y: 53}}},
+		{"torus far corner", config.BoundaryTorus, geom.Point{X: 98, Y: 48}, 5,
+			[]geom.Point{{X: 98, Y: 48}, {X: 98, Y: -2}, {X: -2, Y: 48}, {X: -2, Y: -2}}},
+		{"torus wider than board", config.BoundaryTorus, geom.Point{X: 50, Y: 25}, 60, []geom.Point{
+			{X: 50, Y: 25}, {X: 50, Y: 75}, {X: 50, Y: -25},
+			{X: 150, Y: 25}, {X: 150, Y: 75}, {X: 150, Y: -25},
+			{X: -50, Y: 25}, {X: -50, Y: 75}, {X: -50, Y: -25}}},
+	}
+	for _, tt := range tests {
+		got := boundaryWorld(tt.boundary).SearchCenters(tt.pos, tt.radius)
+		sortPoints(got)
+		sortPoints(tt.want)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func sortPoints(points []geom.Point) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X != points[j].X {
+			return points[i].X < points[j].X
+		}
+		return points[i].Y < points[j].Y
+	})
+}
+
+func TestFindAcrossTorusCorner(t *testing.T) {
+	w := boundaryWorld(config.BoundaryTorus)
+	near := w.Spawn("rabbit", 99, 49)
+	w.Spawn("rabbit", 50, 25)
+
+	found := w.FindNearbyEntities(geom.Point{X: 1, Y: 1}, 5, "rabbit")
+	if len(found) != 1 || found[0] != near {
+		t.Fatalf("found %d rabbits across the corner, want the one at (99, 49)", len(found))
+	}
+	nearest := w.FindNearest(geom.Point{X: 1, Y: 1}, 5, 3, "rabbit", nil)
+	if len(nearest) != 1 || nearest[0] != near {
+		t.Fatalf("FindNearest found %d rabbits across the corner, want the one at (99, 49)", len(nearest))
+	}
+
+	clamped := boundaryWorld(config.BoundaryClamp)
+	clamped.Spawn("rabbit", 99, 49)
+	if found := clamped.FindNearbyEntities(geom.Point{X: 1, Y: 1}, 5, "rabbit"); len(found) != 0 {
+		t.Fatalf("found %d rabbits across the corner of a clamped board", len(found))
+	}
+}
+
+func TestMovesStayIndexed(t *testing.T) {
+	for _, boundary := range []string{config.BoundaryClamp, config.BoundaryReflect, config.BoundaryTorus} {
+		cfg := busyConfig()
+		cfg.World.Boundary = boundary
+		w := newTestWorld(t, cfg, 4, 1)
+		for tick := 0; tick < 100; tick++ {
+			w.Update()
+			for _, entity := range w.Entities {
+				p := entity.GetPosition()
+				if p.X < 0 || p.X >= float64(w.Width) || p.Y < 0 || p.Y >= float64(w.Height) {
+					t.Fatalf("%s: %s %d at %v is off the board", boundary, entity.GetSpecies(), entity.GetID(), p)
+				}
+				indexed := false
+				for _, other := range w.FindNearest(p, 0.001, 8, entity.GetSpecies(), nil) {
+					indexed = indexed || other == entity
+				}
+				if !indexed {
+					t.Fatalf("%s: %s %d at %v is not in the index", boundary, entity.GetSpecies(), entity.GetID(), p)
+				}
+			}
+		}
+	}
+}
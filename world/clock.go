@@ -0,0 +1,85 @@
+package world
+
+import (
+	"fmt"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+type Season int
+
+const (
+	Spring Season = iota
+	Summer
+	Autumn
+	Winter
+)
+
+func (s Season) String() string {
+	switch s {
+	case Spring:
+		return "spring"
+	case Autumn:
+		return "autumn"
+	case Winter:
+		return "winter"
+	default:
+		return "summer"
+	}
+}
+
+// The clock is derived from the tick count, so a restored snapshot
+// resumes at the same time of day and year.
+
+// Season returns the current season; with seasons off it is always
+// summer.
+func (w *World) Season() Season {
+	length := w.Config.Clock.SeasonLength
+	if length <= 0 {
+		return Summer
+	}
+	return Season(w.Tick / length % 4)
+}
+
+// Climate returns how the current season scales growth and upkeep.
+func (w *World) Climate() config.Season {
+	clock := w.Config.Clock
+	if clock.SeasonLength <= 0 {
+		return config.Season{GrassGrowth: 1, GrassSpawn: 1, EnergyLoss: 1}
+	}
+	switch w.Season() {
+	case Spring:
+		return clock.Spring
+	case Autumn:
+		return clock.Autumn
+	case Winter:
+		return clock.Winter
+	default:
+		return clock.Summer
+	}
+}
+
+// Day counts the days since the world was populated, from 1.
+func (w *World) Day() int {
+	if w.Config.Clock.DayLength <= 0 {
+		return 1
+	}
+	return w.Tick/w.Config.Clock.DayLength + 1
+}
+
+// IsNight reports whether the tick falls in the second half of a day.
+func (w *World) IsNight() bool {
+	length := w.Config.Clock.DayLength
+	return length > 0 && w.Tick%length >= length/2
+}
+
+// TimeOfDay formats the clock as a 24-hour time, days starting at six in
+// the morning so that night falls at six in the evening.
+func (w *World) TimeOfDay() string {
+	length := w.Config.Clock.DayLength
+	if length <= 0 {
+		return "12:00"
+	}
+	minutes := (6*60 + w.Tick%length*24*60/length) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
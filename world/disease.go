@@ -0,0 +1,98 @@
+package world
+
+import (
+	"sort"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+)
+
+func isInfected(e Entity) bool {
+	patient, ok := e.(entities.Infectable)
+	return ok && patient.GetHealth() == entities.Infected
+}
+
+func byID(list []Entity) {
+	sort.Slice(list, func(i, j int) bool { return list[i].GetID() < list[j].GetID() })
+}
+
+// outbreak infects the configured number of random animals when the
+// outbreak's tick comes.
+func (w *World) outbreak() {
+	o := w.Config.Outbreak
+	if o.Tick == 0 || o.Tick != w.Tick {
+		return
+	}
+
+	var candidates []Entity
+	for _, entity := range w.Entities {
+		patient, ok := entity.(entities.Infectable)
+		if ok && entity.IsAlive() && entity.GetSpecies() == o.Species && patient.GetHealth() == entities.Susceptible {
+			candidates = append(candidates, entity)
+		}
+	}
+	byID(candidates)
+	w.Rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	infected := 0
+	for _, entity := range candidates {
+		if infected == o.Count {
+			break
+		}
+		if entity.(entities.Infectable).Infect() {
+			infected++
+			w.emit(Infected{Tick: w.Tick, Entity: entity})
+		}
+	}
+}
+
+// spread passes the disease from every infected animal to the susceptible
+// ones of its species nearby, sources in ID order. Animals infected now
+// only pass it on from the next tick.
+func (w *World) spread() {
+	var sources []Entity
+	for _, entity := range w.Entities {
+		if entity.IsAlive() && isInfected(entity) {
+			sources = append(sources, entity)
+		}
+	}
+	byID(sources)
+
+	type contact struct{ entity, source Entity }
+	var caught []contact
+	for _, source := range sources {
+		transmission, radius, _ := source.(entities.Infectable).Contagion()
+		if transmission <= 0 || radius <= 0 {
+			continue
+		}
+		nearby := w.FindNearbyEntities(source.GetPosition(), radius, source.GetSpecies())
+		byID(nearby)
+		for _, entity := range nearby {
+			patient, ok := entity.(entities.Infectable)
+			if !ok || entity == source || patient.GetHealth() != entities.Susceptible {
+				continue
+			}
+			if w.Rand.Float64() < transmission {
+				caught = append(caught, contact{entity, source})
+			}
+		}
+	}
+
+	for _, c := range caught {
+		if c.entity.IsAlive() && c.entity.(entities.Infectable).Infect() {
+			w.emit(Infected{Tick: w.Tick, Entity: c.entity, Source: c.source})
+		}
+	}
+}
+
+// catchFromPrey may infect a predator that has eaten infected prey.
+func (w *World) catchFromPrey(predator, prey Entity) {
+	patient, ok := predator.(entities.Infectable)
+	if !ok || patient.GetHealth() != entities.Susceptible {
+		return
+	}
+	if _, _, fromPrey := patient.Contagion(); fromPrey > 0 && w.Rand.Float64() < fromPrey && patient.Infect() {
+		w.emit(Infected{Tick: w.Tick, Entity: predator, Source: prey})
+	}
+}
@@ -0,0 +1,111 @@
+package world
+
+import (
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// Event is something that happened during a tick. The concrete types are
+// Born, Died, Ate, Grazed, Mated, Infected, Fought and GrassSpawned.
+type Event interface {
+	EventTick() int
+}
+
+// Born is a newborn entering the world. A litter carried by its mother
+// is born with the father unknown, Parents[1] nil.
+type Born struct {
+	Tick    int
+	Entity  Entity
+	Parents [2]Entity
+}
+
+type Died struct {
+	Tick   int
+	Entity Entity
+	Cause  interfaces.Cause
+}
+
+type Ate struct {
+	Tick   int
+	Eater  Entity
+	Food   Entity
+	Energy float64
+}
+
+// Grazed is an animal eating from the grass field at a spot.
+type Grazed struct {
+	Tick   int
+	Eater  Entity
+	At     geom.Point
+	Energy float64
+}
+
+type Mated struct {
+	Tick    int
+	Parents [2]Entity
+}
+
+// Infected is an animal catching a disease, from Source, or in an
+// outbreak when Source is nil.
+type Infected struct {
+	Tick   int
+	Entity Entity
+	Source Entity
+}
+
+// Fought is a territory owner fighting an intruder; the owner gives up
+// its territory if it is the Loser.
+type Fought struct {
+	Tick   int
+	Winner Entity
+	Loser  Entity
+}
+
+type GrassSpawned struct {
+	Tick  int
+	Grass Entity
+}
+
+func (e Born) EventTick() int         { return e.Tick }
+func (e Died) EventTick() int         { return e.Tick }
+func (e Ate) EventTick() int          { return e.Tick }
+func (e Grazed) EventTick() int       { return e.Tick }
+func (e Mated) EventTick() int        { return e.Tick }
+func (e Infected) EventTick() int     { return e.Tick }
+func (e Fought) EventTick() int       { return e.Tick }
+func (e GrassSpawned) EventTick() int { return e.Tick }
+
+type subscription struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe registers fn to be called synchronously for every event, in
+// the order events happen. The returned function removes the subscription.
+func (w *World) Subscribe(fn func(Event)) (unsubscribe func()) {
+	w.nextSubscription++
+	id := w.nextSubscription
+	w.subscribers = append(w.subscribers, subscription{id: id, fn: fn})
+
+	return func() {
+		for i, sub := range w.subscribers {
+			if sub.id == id {
+				w.subscribers = append(w.subscribers[:i:i], w.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (w *World) emit(e Event) {
+	for _, sub := range w.subscribers {
+		sub.fn(e)
+	}
+}
+
+// emitIfDied reports the death of entity if it is no longer alive.
+func (w *World) emitIfDied(entity Entity) {
+	if !entity.IsAlive() {
+		w.emit(Died{Tick: w.Tick, Entity: entity, Cause: entity.GetDeathCause()})
+	}
+}
@@ -0,0 +1,168 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+)
+
+// fieldSpecies is the diet entry that grazes the field.
+const fieldSpecies = "grass"
+
+// Field is the grass layer used when grass.mode is "field": a grid of
+// cells, each holding some biomass up to its capacity. Cells regrow
+// logistically, peaking at Regrowth per tick when half full, and exchange
+// biomass with their neighbours, so a grazed-out cell only recovers once
+// grass spreads back into it.
+type Field struct {
+	Cols      int     `json:"cols"`
+	Rows      int     `json:"rows"`
+	CellSize  float64 `json:"cellSize"`
+	Diffusion float64 `json:"diffusion"`
+	// Torus joins opposite edges for diffusion.
+	Torus bool `json:"torus"`
+
+	Biomass  []float64 `json:"biomass"`
+	Capacity []float64 `json:"capacity"`
+	Regrowth []float64 `json:"regrowth"`
+
+	next []float64
+}
+
+func newField(width, height int, cfg config.Grass, torus bool, rng *rand.Rand) *Field {
+	cols := int(math.Ceil(float64(width) / cfg.CellSize))
+	rows := int(math.Ceil(float64(height) / cfg.CellSize))
+	n := cols * rows
+
+	f := &Field{
+		Cols:      cols,
+		Rows:      rows,
+		CellSize:  cfg.CellSize,
+		Diffusion: cfg.Diffusion,
+		Torus:     torus,
+		Biomass:   make([]float64, n),
+		Capacity:  make([]float64, n),
+		Regrowth:  make([]float64, n),
+	}
+	for i := range f.Capacity {
+		f.Capacity[i] = cfg.MaxAmountMin + rng.Float64()*(cfg.MaxAmountMax-cfg.MaxAmountMin)
+		f.Regrowth[i] = cfg.GrowthRateMin + rng.Float64()*(cfg.GrowthRateMax-cfg.GrowthRateMin)
+	}
+	return f
+}
+
+// check reports a field whose slices do not match its size, e.g. from a
+// damaged snapshot.
+func (f *Field) check() error {
+	n := f.Cols * f.Rows
+	if f.Cols <= 0 || f.Rows <= 0 || f.CellSize <= 0 {
+		return fmt.Errorf("field has invalid size %dx%d with cell size %g", f.Cols, f.Rows, f.CellSize)
+	}
+	if len(f.Biomass) != n || len(f.Capacity) != n || len(f.Regrowth) != n {
+		return fmt.Errorf("field has %d/%d/%d cells, want %d", len(f.Biomass), len(f.Capacity), len(f.Regrowth), n)
+	}
+	return nil
+}
+
+// Cell returns the index of the cell containing p.
+func (f *Field) Cell(p geom.Point) int {
+	col := min(max(int(p.X/f.CellSize), 0), f.Cols-1)
+	row := min(max(int(p.Y/f.CellSize), 0), f.Rows-1)
+	return row*f.Cols + col
+}
+
+// Center returns the middle of cell i.
+func (f *Field) Center(i int) geom.Point {
+	return geom.Point{
+		X: (float64(i%f.Cols) + 0.5) * f.CellSize,
+		Y: (float64(i/f.Cols) + 0.5) * f.CellSize,
+	}
+}
+
+// Total returns the biomass of the whole field.
+func (f *Field) Total() float64 {
+	total := 0.0
+	for _, b := range f.Biomass {
+		total += b
+	}
+	return total
+}
+
+// Grazable counts the cells holding at least bite.
+func (f *Field) Grazable(bite float64) int {
+	n := 0
+	for _, b := range f.Biomass {
+		if b >= bite {
+			n++
+		}
+	}
+	return n
+}
+
+// Seed fills each cell to capacity with probability fraction.
+func (f *Field) Seed(fraction float64, rng *rand.Rand) {
+	for i := range f.Biomass {
+		if rng.Float64() < fraction {
+			f.Biomass[i] = f.Capacity[i]
+		}
+	}
+}
+
+// Graze removes up to want from the cell containing p and returns what
+// was taken.
+func (f *Field) Graze(p geom.Point, want float64) float64 {
+	i := f.Cell(p)
+	taken := math.Min(want, f.Biomass[i])
+	f.Biomass[i] -= taken
+	return taken
+}
+
+// Update diffuses biomass between neighbouring cells and regrows every
+// cell, at growth times its usual rate. Off the board edges, unless on a
+// torus, a cell sees itself, so no biomass leaks out. Cells without
+// capacity, such as water and rock, hold no grass and are walled off the
+// same way.
+func (f *Field) Update(growth float64) {
+	if len(f.next) != len(f.Biomass) {
+		f.next = make([]float64, len(f.Biomass))
+	}
+
+	for row := 0; row < f.Rows; row++ {
+		for col := 0; col < f.Cols; col++ {
+			i := row*f.Cols + col
+			capacity := f.Capacity[i]
+			if capacity <= 0 {
+				f.next[i] = 0
+				continue
+			}
+
+			b := f.Biomass[i]
+			mean := (f.neighbour(col-1, row, b) + f.neighbour(col+1, row, b) +
+				f.neighbour(col, row-1, b) + f.neighbour(col, row+1, b)) / 4
+			b += f.Diffusion * (mean - b)
+
+			if b > 0 {
+				b += 4 * growth * f.Regrowth[i] * b / capacity * (1 - b/capacity)
+				b = math.Min(b, capacity)
+			}
+			f.next[i] = b
+		}
+	}
+	f.Biomass, f.next = f.next, f.Biomass
+}
+
+func (f *Field) neighbour(col, row int, self float64) float64 {
+	if f.Torus {
+		col = (col + f.Cols) % f.Cols
+		row = (row + f.Rows) % f.Rows
+	} else if col < 0 || col >= f.Cols || row < 0 || row >= f.Rows {
+		return self
+	}
+	if i := row*f.Cols + col; f.Capacity[i] > 0 {
+		return f.Biomass[i]
+	}
+	return self
+}
@@ -0,0 +1,126 @@
+package world
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+)
+
+// testField is a 20x10 field of capacity 100 with a wall of water down
+// column 7 and a lake in the middle of the right part.
+func testField(torus bool) *Field {
+	cfg := config.Default().Grass
+	cfg.CellSize = 1
+	cfg.Diffusion = 0.4
+	f := newField(20, 10, cfg, torus, rand.New(rand.NewPCG(1, 1)))
+	for i := range f.Capacity {
+		col, row := i%f.Cols, i/f.Cols
+		f.Capacity[i] = 100
+		if col == 7 || (col >= 12 && col <= 15 && row >= 3 && row <= 6) {
+			f.Capacity[i] = 0
+		}
+	}
+	return f
+}
+
+func TestFieldDiffusionConserves(t *testing.T) {
+	for _, torus := range []bool{false, true} {
+		f := testField(torus)
+		rng := rand.New(rand.NewPCG(2, 2))
+		for i := range f.Biomass {
+			f.Biomass[i] = rng.Float64() * f.Capacity[i]
+		}
+		// Only the left part is grassed, so nothing may cross the wall.
+		left := 0.0
+		for i := range f.Biomass {
+			if i%f.Cols < 7 {
+				left += f.Biomass[i]
+			}
+		}
+		total := f.Total()
+
+		for tick := 0; tick < 500; tick++ {
+			f.Update(0)
+		}
+		if math.Abs(f.Total()-total) > 1e-9*total {
+			t.Errorf("torus %v: diffusion changed the biomass from %g to %g", torus, total, f.Total())
+		}
+		afterLeft := 0.0
+		for i := range f.Biomass {
+			if i%f.Cols < 7 {
+				afterLeft += f.Biomass[i]
+			}
+		}
+		if !torus && math.Abs(afterLeft-left) > 1e-9*left {
+			t.Errorf("biomass crossed the water: the left part went from %g to %g", left, afterLeft)
+		}
+		checkBarren(t, f)
+	}
+}
+
+func TestFieldCap(t *testing.T) {
+	f := testField(false)
+	for i := range f.Capacity {
+		if i%3 == 0 && f.Capacity[i] > 0 {
+			f.Capacity[i] = 20
+		}
+	}
+	f.Seed(0.5, rand.New(rand.NewPCG(3, 3)))
+	for tick := 0; tick < 300; tick++ {
+		f.Update(1.5)
+		for i, b := range f.Biomass {
+			if b > f.Capacity[i] || b < 0 {
+				t.Fatalf("tick %d: cell %d holds %g with capacity %g", tick, i, b, f.Capacity[i])
+			}
+		}
+	}
+	checkBarren(t, f)
+	if f.Grazable(19) == 0 {
+		t.Fatal("the field never grew back")
+	}
+}
+
+// TestFieldDrainsBarrenCells checks that biomass on cells without capacity,
+// e.g. from an old snapshot, is cleared rather than spread.
+func TestFieldDrainsBarrenCells(t *testing.T) {
+	f := testField(false)
+	for i := range f.Biomass {
+		f.Biomass[i] = 50
+	}
+	f.Update(0)
+	checkBarren(t, f)
+	for i, b := range f.Biomass {
+		if f.Capacity[i] > 0 && b != 50 {
+			t.Fatalf("cell %d next to water went from 50 to %g", i, b)
+		}
+	}
+}
+
+func checkBarren(t *testing.T, f *Field) {
+	t.Helper()
+	for i, b := range f.Biomass {
+		if f.Capacity[i] == 0 && b != 0 {
+			t.Fatalf("cell %d without capacity holds %g", i, b)
+		}
+	}
+}
+
+func TestFieldOnTerrain(t *testing.T) {
+	cfg := busyConfig()
+	cfg.Grass.Mode = config.GrassField
+	cfg.Terrain.Map = config.TerrainNoise
+	w := newTestWorld(t, cfg, 5, 1)
+	barren := 0
+	for _, c := range w.Field.Capacity {
+		if c == 0 {
+			barren++
+		}
+	}
+	if barren == 0 {
+		t.Fatal("the terrain has no water or rock")
+	}
+	run(w, 200)
+	checkBarren(t, w.Field)
+}
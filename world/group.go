@@ -0,0 +1,55 @@
+package world
+
+import "github.com/j-bisew/foxes-rabbits-simulation/config"
+
+// addGroups counts the herds and packs of every species that forms them:
+// animals within the species' group radius of each other, directly or
+// through other members, make up one group.
+func (w *World) addGroups(s *TickStats) {
+	members := make(map[string][]Entity)
+	for _, entity := range w.Entities {
+		species := entity.GetSpecies()
+		g := w.Config.Species[species].Group
+		if entity.IsAlive() && (g.Kind == config.GroupHerd || g.Kind == config.GroupPack) {
+			members[species] = append(members[species], entity)
+		}
+	}
+
+	for species, list := range members {
+		byID(list)
+		index := make(map[uint64]int, len(list))
+		for i, entity := range list {
+			index[entity.GetID()] = i
+		}
+		parent := make([]int, len(list))
+		for i := range parent {
+			parent[i] = i
+		}
+		var root func(i int) int
+		root = func(i int) int {
+			for parent[i] != i {
+				parent[i] = parent[parent[i]]
+				i = parent[i]
+			}
+			return i
+		}
+
+		radius := w.Config.Species[species].Group.Radius
+		for i, entity := range list {
+			for _, other := range w.FindNearbyEntities(entity.GetPosition(), radius, species) {
+				if j, ok := index[other.GetID()]; ok {
+					parent[root(j)] = root(i)
+				}
+			}
+		}
+
+		entry := s.Species[species]
+		for i := range list {
+			if root(i) == i {
+				entry.Groups++
+			}
+		}
+		entry.MeanGroupSize = float64(len(list)) / float64(entry.Groups)
+		s.Species[species] = entry
+	}
+}
@@ -0,0 +1,63 @@
+package world
+
+import (
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/quadtree"
+)
+
+// spatialIndex keeps one quadtree per species, so a fox looking for
+// rabbits never walks through the grass and the cost of a query follows
+// the size of the population it targets.
+type spatialIndex struct {
+	boundary geom.Rectangle
+	trees    map[string]*quadtree.QuadTree
+}
+
+func newSpatialIndex(boundary geom.Rectangle) *spatialIndex {
+	return &spatialIndex{
+		boundary: boundary,
+		trees:    make(map[string]*quadtree.QuadTree),
+	}
+}
+
+// tree returns the species' tree, or nil if none was ever indexed.
+func (s *spatialIndex) tree(species string) *quadtree.QuadTree {
+	return s.trees[species]
+}
+
+func (s *spatialIndex) Insert(entity Entity) {
+	species := entity.GetSpecies()
+	qt := s.trees[species]
+	if qt == nil {
+		qt = quadtree.NewQuadTree(10, s.boundary)
+		s.trees[species] = qt
+	}
+	qt.Insert(entity.GetPosition(), entity)
+}
+
+func (s *spatialIndex) Remove(entity Entity) {
+	if qt := s.trees[entity.GetSpecies()]; qt != nil {
+		qt.Remove(entity.GetPosition(), entity)
+	}
+}
+
+func (s *spatialIndex) Move(from, to geom.Point, entity Entity) {
+	if qt := s.trees[entity.GetSpecies()]; qt != nil {
+		qt.Move(from, to, entity)
+	}
+}
+
+func (s *spatialIndex) Clear() {
+	for _, qt := range s.trees {
+		qt.Clear()
+	}
+}
+
+// Len returns the number of indexed entities of all species.
+func (s *spatialIndex) Len() int {
+	n := 0
+	for _, qt := range s.trees {
+		n += qt.Len()
+	}
+	return n
+}
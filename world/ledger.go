@@ -0,0 +1,95 @@
+package world
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// Ledger accounts for the energy held by animals during one tick. Every
+// change is booked under a flow where it is made, so that
+//
+//	Held = Opening + Eaten - Upkeep - Sickness - Fleeing - Courtship - Fights - Died
+//
+// up to rounding. Births are not a flow: young are born with energy their
+// parents set aside, so a birth that made energy out of nothing shows up
+// as an Imbalance. Gestation moves energy from mothers into their
+// litters' provision, which Held counts, so it is not in the sum either.
+type Ledger struct {
+	// Opening and Held are the energy held at the start and end of the
+	// tick, counting the provision for the litters females carry.
+	Opening float64 `json:"opening"`
+	Held    float64 `json:"held"`
+	// Eaten is the energy gained from food, after the cap at max energy.
+	Eaten float64 `json:"eaten"`
+	// Upkeep, Sickness, Fleeing and Gestation are what the animals pay as
+	// they act: their metabolism, the extra a disease costs them, the
+	// cost of running from predators and the provision for litters.
+	Upkeep    float64 `json:"upkeep"`
+	Sickness  float64 `json:"sickness"`
+	Fleeing   float64 `json:"fleeing"`
+	Gestation float64 `json:"gestation"`
+	// Courtship is the cost of mating, Fights that of territorial fights.
+	Courtship float64 `json:"courtship"`
+	Fights    float64 `json:"fights"`
+	// Died is the energy held by the animals that died.
+	Died      float64 `json:"died"`
+	Imbalance float64 `json:"imbalance"`
+}
+
+// held is the energy an entity holds for the ledger; only animals count.
+func held(e Entity) float64 {
+	if _, ok := e.(interfaces.Feeder); !ok {
+		return 0
+	}
+	energy := e.GetEnergy()
+	if bearer, ok := e.(entities.Bearer); ok {
+		energy += bearer.Carried()
+	}
+	return energy
+}
+
+func heldBy(living []Entity) float64 {
+	total := 0.0
+	for _, e := range living {
+		total += held(e)
+	}
+	return total
+}
+
+func (l *Ledger) open(living []Entity) {
+	*l = Ledger{Opening: heldBy(living)}
+}
+
+func (l *Ledger) close(living []Entity) {
+	l.Held = heldBy(living)
+	l.Imbalance = l.Held - (l.Opening + l.Eaten - l.Upkeep - l.Sickness - l.Fleeing - l.Courtship - l.Fights - l.Died)
+}
+
+// Charge books energy an animal paid for flow while acting.
+func (w *World) Charge(flow interfaces.Flow, amount float64) {
+	switch flow {
+	case interfaces.Upkeep:
+		w.Ledger.Upkeep += amount
+	case interfaces.Sickness:
+		w.Ledger.Sickness += amount
+	case interfaces.Fleeing:
+		w.Ledger.Fleeing += amount
+	case interfaces.Gestation:
+		w.Ledger.Gestation += amount
+	default:
+		panic(fmt.Sprintf("world: charge for unknown flow %q", flow))
+	}
+}
+
+// Check reports an imbalance beyond rounding error.
+func (l Ledger) Check() error {
+	scale := math.Max(1, l.Opening+l.Eaten+math.Abs(l.Upkeep)+math.Abs(l.Sickness)+l.Fleeing+l.Courtship+l.Fights+math.Abs(l.Died))
+	if math.Abs(l.Imbalance) > 1e-9*scale {
+		return fmt.Errorf("energy is off by %g: opened with %g, ate %g, upkeep %g, sickness %g, fleeing %g, courtship %g, fights %g, died %g, holding %g",
+			l.Imbalance, l.Opening, l.Eaten, l.Upkeep, l.Sickness, l.Fleeing, l.Courtship, l.Fights, l.Died, l.Held)
+	}
+	return nil
+}
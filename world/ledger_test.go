@@ -0,0 +1,147 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// ledgerConfig breeds both species in litters, with fathers paying part
+// of the young's energy, and keeps the gestation of foxes short.
+func ledgerConfig() *config.Config {
+	cfg := busyConfig()
+	rabbit := cfg.Species["rabbit"]
+	rabbit.Reproduction.PaternalShare = 0.3
+	cfg.Species["rabbit"] = rabbit
+	fox := cfg.Species["fox"]
+	fox.Reproduction.PaternalShare = 0.5
+	fox.Reproduction.LitterMin, fox.Reproduction.LitterMax = 2, 3
+	fox.Reproduction.LitterWeights = nil
+	cfg.Species["fox"] = fox
+	return cfg
+}
+
+func TestLedgerBalancesEveryTick(t *testing.T) {
+	setups := map[string]func() *config.Config{
+		"litters": ledgerConfig,
+		"no gestation": func() *config.Config {
+			cfg := ledgerConfig()
+			for name, s := range cfg.Species {
+				s.Reproduction.Gestation = 0
+				cfg.Species[name] = s
+			}
+			return cfg
+		},
+		"field": func() *config.Config {
+			cfg := ledgerConfig()
+			cfg.Grass.Mode = config.GrassField
+			return cfg
+		},
+	}
+	for name, setup := range setups {
+		t.Run(name, func(t *testing.T) {
+			var total Ledger
+			births := make(map[string]int)
+			fights := 0
+			deaths := make(map[interfaces.Cause]int)
+			for seed := uint64(1); seed <= 2; seed++ {
+				w := newTestWorld(t, setup(), seed, 4)
+				w.Subscribe(func(e Event) {
+					switch e := e.(type) {
+					case Born:
+						births[e.Entity.GetSpecies()]++
+					case Fought:
+						fights++
+					case Died:
+						deaths[e.Cause]++
+					}
+				})
+				for tick := 0; tick < 600; tick++ {
+					w.Update()
+					if err := w.Ledger.Check(); err != nil {
+						t.Fatalf("seed %d, tick %d: %v", seed, w.Tick, err)
+					}
+					total.Eaten += w.Ledger.Eaten
+					total.Upkeep += w.Ledger.Upkeep
+					total.Sickness += w.Ledger.Sickness
+					total.Fleeing += w.Ledger.Fleeing
+					total.Gestation += w.Ledger.Gestation
+					total.Courtship += w.Ledger.Courtship
+					total.Fights += w.Ledger.Fights
+					total.Died += w.Ledger.Died
+				}
+			}
+
+			// The runs must have gone through everything the ledger books.
+			if births["rabbit"] == 0 || births["fox"] == 0 || fights == 0 {
+				t.Fatalf("births by species %v and %d fights in all runs", births, fights)
+			}
+			if deaths[interfaces.Starvation] == 0 || deaths[interfaces.Predation] == 0 {
+				t.Fatalf("deaths by cause: %v", deaths)
+			}
+			if total.Eaten <= 0 || total.Upkeep <= 0 || total.Sickness <= 0 || total.Fleeing <= 0 ||
+				total.Courtship <= 0 || total.Fights <= 0 || total.Died == 0 {
+				t.Fatalf("a flow was never booked: %+v", total)
+			}
+			if name == "no gestation" && total.Gestation != 0 {
+				t.Fatalf("booked %g gestation without gestation", total.Gestation)
+			}
+			if name != "no gestation" && total.Gestation <= 0 {
+				t.Fatal("no gestation was booked")
+			}
+		})
+	}
+}
+
+// TestYoungArePaidFor checks that every young is born with exactly the
+// energy its parents set aside for it.
+func TestYoungArePaidFor(t *testing.T) {
+	cfg := ledgerConfig()
+	w := newTestWorld(t, cfg, 2, 1)
+	born := 0
+	w.Subscribe(func(e Event) {
+		b, ok := e.(Born)
+		if !ok {
+			return
+		}
+		born++
+		want := cfg.Species[b.Entity.GetSpecies()].Reproduction.OffspringEnergy
+		if got := b.Entity.GetEnergy(); math.Abs(got-want) > 1e-9*want {
+			t.Errorf("tick %d: %s born with %g, want %g", b.Tick, b.Entity.GetSpecies(), got, want)
+		}
+	})
+	run(w, 600)
+	if born == 0 {
+		t.Fatal("nothing was born")
+	}
+}
+
+func TestLedgerCatchesUnbookedEnergy(t *testing.T) {
+	w := newTestWorld(t, ledgerConfig(), 1, 1)
+	var animal Entity
+	for _, e := range w.Entities {
+		if e.GetSpecies() == "fox" {
+			animal = e
+			break
+		}
+	}
+
+	w.Ledger.open(w.Entities)
+	animal.UpdateEnergy(-5)
+	w.Charge(interfaces.Upkeep, 5)
+	w.Ledger.close(w.Entities)
+	if err := w.Ledger.Check(); err != nil {
+		t.Fatalf("booked upkeep: %v", err)
+	}
+
+	for _, change := range []float64{5, -5, 1e-3} {
+		w.Ledger.open(w.Entities)
+		animal.UpdateEnergy(change)
+		w.Ledger.close(w.Entities)
+		if err := w.Ledger.Check(); err == nil {
+			t.Errorf("an unbooked change of %g went unnoticed", change)
+		}
+	}
+}
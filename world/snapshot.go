@@ -0,0 +1,138 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/terrain"
+)
+
+const snapshotVersion = 1
+
+type snapshot struct {
+	Version        int            `json:"version"`
+	Width          int            `json:"width"`
+	Height         int            `json:"height"`
+	GrassSpawnRate float64        `json:"grassSpawnRate"`
+	MaxGrassCount  int            `json:"maxGrassCount"`
+	Seed           uint64         `json:"seed"`
+	Tick           int            `json:"tick"`
+	NextID         uint64         `json:"nextId"`
+	RandState      []byte         `json:"randState"`
+	Config         *config.Config `json:"config"`
+	Entities       []entityState  `json:"entities"`
+	Field          *Field         `json:"field,omitempty"`
+	Terrain        *terrain.Map   `json:"terrain,omitempty"`
+}
+
+type entityState struct {
+	Species string          `json:"species"`
+	State   json.RawMessage `json:"state"`
+}
+
+// Save writes the complete state of the world, including its random
+// source, so that Load can resume the run exactly where it stopped.
+func (w *World) Save(out io.Writer) error {
+	randState, err := w.source.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("world: save random state: %w", err)
+	}
+
+	snap := snapshot{
+		Version:        snapshotVersion,
+		Width:          w.Width,
+		Height:         w.Height,
+		GrassSpawnRate: w.GrassSpawnRate,
+		MaxGrassCount:  w.MaxGrassCount,
+		Seed:           w.Seed,
+		Tick:           w.Tick,
+		NextID:         w.nextID,
+		RandState:      randState,
+		Config:         w.Config,
+		Entities:       make([]entityState, 0, len(w.Entities)),
+		Field:          w.Field,
+		Terrain:        w.Terrain,
+	}
+
+	for _, entity := range w.Entities {
+		if !entity.IsAlive() {
+			continue
+		}
+		state, err := json.Marshal(entity)
+		if err != nil {
+			return fmt.Errorf("world: save %s: %w", entity.GetSpecies(), err)
+		}
+		snap.Entities = append(snap.Entities, entityState{Species: entity.GetSpecies(), State: state})
+	}
+
+	return json.NewEncoder(out).Encode(snap)
+}
+
+// Load reads a world written by Save.
+func Load(in io.Reader) (*World, error) {
+	// Settings added after a snapshot was written keep their defaults.
+	snap := snapshot{Config: config.Default()}
+	if err := json.NewDecoder(in).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("world: load snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("world: unsupported snapshot version %d", snap.Version)
+	}
+	if snap.Config == nil {
+		return nil, fmt.Errorf("world: snapshot has no config")
+	}
+	if err := snap.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("world: snapshot config: %w", err)
+	}
+
+	snap.Config.World.Width = snap.Width
+	snap.Config.World.Height = snap.Height
+	w := NewWorld(snap.Config, snap.Seed)
+	w.GrassSpawnRate = snap.GrassSpawnRate
+	w.MaxGrassCount = snap.MaxGrassCount
+	w.Tick = snap.Tick
+	if snap.Field != nil {
+		if err := snap.Field.check(); err != nil {
+			return nil, fmt.Errorf("world: load %w", err)
+		}
+		w.Field = snap.Field
+	}
+	if snap.Terrain != nil {
+		if err := snap.Terrain.Check(); err != nil {
+			return nil, fmt.Errorf("world: load %w", err)
+		}
+		w.Terrain = snap.Terrain
+	}
+
+	if err := w.source.UnmarshalBinary(snap.RandState); err != nil {
+		return nil, fmt.Errorf("world: load random state: %w", err)
+	}
+
+	// Constructors may draw random numbers; those draws must not touch the
+	// restored source, and their results are overwritten anyway.
+	scratch := rand.New(rand.NewPCG(0, 0))
+
+	for i, state := range snap.Entities {
+		info, ok := entities.LookupSpecies(state.Species)
+		if !ok {
+			return nil, fmt.Errorf("world: entity %d has unknown species %q", i, state.Species)
+		}
+		entity := info.New(0, 0, w.Config, scratch)
+		if err := json.Unmarshal(state.State, entity); err != nil {
+			return nil, fmt.Errorf("world: load %s %d: %w", state.Species, i, err)
+		}
+		if thinker, ok := entity.(entities.Thinker); ok {
+			thinker.SetBrain(entities.NewBrain(w.Config.Species[state.Species].Brain))
+		}
+		w.AddEntity(entity)
+	}
+	if snap.NextID > w.nextID {
+		w.nextID = snap.NextID
+	}
+
+	return w, nil
+}
@@ -0,0 +1,95 @@
+package world
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+)
+
+func TestLoadContinuesRun(t *testing.T) {
+	for name, cfg := range testConfigs() {
+		t.Run(name, func(t *testing.T) {
+			straight := newTestWorld(t, cfg(), 9, 1)
+			run(straight, 200)
+
+			saved := newTestWorld(t, cfg(), 9, 1)
+			run(saved, 100)
+			var buf bytes.Buffer
+			if err := saved.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			resumed, err := Load(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			run(resumed, 100)
+
+			if !bytes.Equal(state(t, straight), state(t, resumed)) {
+				t.Error("a saved and loaded run differs from one that never stopped")
+			}
+		})
+	}
+}
+
+func TestSaveLoadSave(t *testing.T) {
+	w := newTestWorld(t, busyConfig(), 3, 1)
+	run(w, 120)
+	var first bytes.Buffer
+	if err := w.Save(&first); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var second bytes.Buffer
+	if err := loaded.Save(&second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("saving a loaded world changes the snapshot")
+	}
+}
+
+func TestLoadKeepsConfiguredBrain(t *testing.T) {
+	cfg := busyConfig()
+	rabbit := cfg.Species["rabbit"]
+	rabbit.Brain = "wanderer"
+	cfg.Species["rabbit"] = rabbit
+	w := newTestWorld(t, cfg, 2, 1)
+	run(w, 10)
+
+	var buf bytes.Buffer
+	if err := w.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"rabbit": fmt.Sprintf("%T", entities.NewBrain("wanderer")),
+		"fox":    fmt.Sprintf("%T", entities.NewBrain(entities.DefaultBrain)),
+	}
+	checked := 0
+	for _, entity := range loaded.Entities {
+		var brain entities.Brain
+		switch animal := entity.(type) {
+		case *entities.Rabbit:
+			brain = animal.Brain
+		case *entities.Fox:
+			brain = animal.Brain
+		default:
+			continue
+		}
+		if got := fmt.Sprintf("%T", brain); got != want[entity.GetSpecies()] {
+			t.Fatalf("loaded %s %d has brain %s, want %s", entity.GetSpecies(), entity.GetID(), got, want[entity.GetSpecies()])
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatal("no animals were loaded")
+	}
+}
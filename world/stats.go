@@ -0,0 +1,171 @@
+package world
+
+import (
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// SpeciesStats describes one species at the end of a tick.
+type SpeciesStats struct {
+	Count       int                      `json:"count"`
+	TotalEnergy float64                  `json:"totalEnergy"`
+	MeanEnergy  float64                  `json:"meanEnergy"`
+	Births      int                      `json:"births"`
+	Deaths      map[interfaces.Cause]int `json:"deaths"`
+	// Stages counts the living animals by life stage; plants have none.
+	Stages  map[string]int `json:"stages,omitempty"`
+	MeanAge float64        `json:"meanAge,omitempty"`
+	// Males and Females count the living animals by sex, Pregnant the
+	// females carrying a litter.
+	Males    int `json:"males,omitempty"`
+	Females  int `json:"females,omitempty"`
+	Pregnant int `json:"pregnant,omitempty"`
+	// Health counts the living animals by disease state, Infections the
+	// animals that caught the disease during the tick.
+	Health     map[string]int `json:"health,omitempty"`
+	Infections int            `json:"infections,omitempty"`
+	// Groups counts the herds or packs of a species that forms them,
+	// MeanGroupSize is their mean number of members.
+	Groups        int     `json:"groups,omitempty"`
+	MeanGroupSize float64 `json:"meanGroupSize,omitempty"`
+	// Sheltered counts the living animals inside their home.
+	Sheltered int `json:"sheltered,omitempty"`
+	// Territories counts the territories held, MeanTerritorySize is their
+	// mean area on passable ground and TerritoryOverlap the share of the
+	// claimed ground in more than one; Fights counts the fights over them
+	// during the tick.
+	Territories       int     `json:"territories,omitempty"`
+	MeanTerritorySize float64 `json:"meanTerritorySize,omitempty"`
+	TerritoryOverlap  float64 `json:"territoryOverlap,omitempty"`
+	Fights            int     `json:"fights,omitempty"`
+}
+
+// TickStats is what a Recorder receives after every tick.
+type TickStats struct {
+	Tick    int                     `json:"tick"`
+	Season  string                  `json:"season"`
+	Night   bool                    `json:"night"`
+	Species map[string]SpeciesStats `json:"species"`
+	// GrassBiomass is the total amount of grass on the board: the energy
+	// of the plant species, or the biomass of the grass field.
+	GrassBiomass float64 `json:"grassBiomass"`
+	// Energy accounts for the animals' energy during the tick.
+	Energy Ledger `json:"energy"`
+}
+
+// Recorder is notified at the end of every World.Update.
+type Recorder interface {
+	Record(stats *TickStats)
+}
+
+func (w *World) AddRecorder(r Recorder) {
+	w.Recorders = append(w.Recorders, r)
+}
+
+func newTickStats(tick int) *TickStats {
+	stats := &TickStats{
+		Tick:    tick,
+		Species: make(map[string]SpeciesStats),
+	}
+	for _, info := range entities.AllSpecies() {
+		stats.Species[info.Name] = SpeciesStats{Deaths: make(map[interfaces.Cause]int)}
+	}
+	return stats
+}
+
+// collectStats counts births and deaths for the recorders.
+func (w *World) collectStats(e Event) {
+	if w.stats == nil {
+		return
+	}
+	switch e := e.(type) {
+	case Born:
+		species := e.Entity.GetSpecies()
+		entry := w.stats.Species[species]
+		entry.Births++
+		w.stats.Species[species] = entry
+	case Died:
+		w.stats.Species[e.Entity.GetSpecies()].Deaths[e.Cause]++
+	case Infected:
+		species := e.Entity.GetSpecies()
+		entry := w.stats.Species[species]
+		entry.Infections++
+		w.stats.Species[species] = entry
+	case Fought:
+		species := e.Winner.GetSpecies()
+		entry := w.stats.Species[species]
+		entry.Fights++
+		w.stats.Species[species] = entry
+	}
+}
+
+// finish fills in the populations, energies and ages of the living
+// entities.
+func (s *TickStats) finish(living []Entity) {
+	totalAge := make(map[string]int)
+	for _, entity := range living {
+		species := entity.GetSpecies()
+		if info, ok := entities.LookupSpecies(species); ok && info.Structure {
+			continue
+		}
+		entry := s.Species[species]
+		entry.Count++
+		entry.TotalEnergy += entity.GetEnergy()
+		if ageing, ok := entity.(entities.Ageing); ok {
+			if entry.Stages == nil {
+				entry.Stages = make(map[string]int)
+			}
+			entry.Stages[ageing.Stage().String()]++
+			totalAge[species] += ageing.GetAge()
+		}
+		if bearer, ok := entity.(entities.Bearer); ok {
+			if bearer.GetSex() == entities.Male {
+				entry.Males++
+			} else {
+				entry.Females++
+			}
+			if bearer.Pregnant() {
+				entry.Pregnant++
+			}
+		}
+		if patient, ok := entity.(entities.Infectable); ok {
+			if entry.Health == nil {
+				entry.Health = make(map[string]int)
+			}
+			entry.Health[patient.GetHealth().String()]++
+		}
+		if homing, ok := entity.(entities.Homing); ok && homing.IsSheltered() {
+			entry.Sheltered++
+		}
+		s.Species[species] = entry
+	}
+
+	for species, entry := range s.Species {
+		if entry.Count > 0 {
+			entry.MeanEnergy = entry.TotalEnergy / float64(entry.Count)
+			entry.MeanAge = float64(totalAge[species]) / float64(entry.Count)
+		}
+		s.Species[species] = entry
+	}
+
+	s.GrassBiomass = 0
+	for _, info := range entities.AllSpecies() {
+		if !info.Animal {
+			s.GrassBiomass += s.Species[info.Name].TotalEnergy
+		}
+	}
+}
+
+// addField accounts the grass field as the grass species: its count is
+// the cells holding at least a bite, its energy the total biomass.
+func (s *TickStats) addField(f *Field, bite float64) {
+	entry := s.Species[fieldSpecies]
+	entry.Count = f.Grazable(bite)
+	entry.TotalEnergy = f.Total()
+	entry.MeanEnergy = 0
+	if entry.Count > 0 {
+		entry.MeanEnergy = entry.TotalEnergy / float64(entry.Count)
+	}
+	s.Species[fieldSpecies] = entry
+	s.GrassBiomass = f.Total()
+}
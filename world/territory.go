@@ -0,0 +1,101 @@
+package world
+
+import (
+	"math"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// territoryCell is the side of the grid squares territories are measured
+// in for the stats.
+const territoryCell = 2.0
+
+// mapTerritories lists the territories the living animals hold, per
+// species and by owner ID.
+func (w *World) mapTerritories() {
+	w.territories = make(map[string][]interfaces.Territory)
+	var owners []Entity
+	for _, entity := range w.Entities {
+		if _, ok := entity.(entities.Territorial); ok && entity.IsAlive() {
+			owners = append(owners, entity)
+		}
+	}
+	byID(owners)
+	for _, owner := range owners {
+		if t, ok := owner.(entities.Territorial).GetTerritory(); ok {
+			t.Owner = owner
+			w.territories[owner.GetSpecies()] = append(w.territories[owner.GetSpecies()], t)
+		}
+	}
+}
+
+func (w *World) Territories(species string) []interfaces.Territory {
+	return w.territories[species]
+}
+
+// addTerritories measures the territories of every species that holds
+// them: their mean size on passable ground and the share of the claimed
+// ground that more than one of them covers.
+func (w *World) addTerritories(s *TickStats) {
+	for species, list := range w.territories {
+		cover := make(map[int]int)
+		total := 0
+		for _, t := range list {
+			cells := w.territoryCells(t)
+			total += len(cells)
+			for _, cell := range cells {
+				cover[cell]++
+			}
+		}
+		shared := 0
+		for _, n := range cover {
+			if n > 1 {
+				shared++
+			}
+		}
+
+		entry := s.Species[species]
+		entry.Territories = len(list)
+		entry.MeanTerritorySize = float64(total) * territoryCell * territoryCell / float64(len(list))
+		if len(cover) > 0 {
+			entry.TerritoryOverlap = float64(shared) / float64(len(cover))
+		}
+		s.Species[species] = entry
+	}
+}
+
+// territoryCells lists the grid squares whose centres lie in t on
+// passable ground.
+func (w *World) territoryCells(t interfaces.Territory) []int {
+	cols := int(math.Ceil(float64(w.Width) / territoryCell))
+	rows := int(math.Ceil(float64(w.Height) / territoryCell))
+	torus := w.Config.World.Boundary == config.BoundaryTorus
+	reach := int(math.Ceil(t.Radius/territoryCell)) + 1
+	col0, row0 := int(t.Center.X/territoryCell), int(t.Center.Y/territoryCell)
+
+	seen := make(map[int]bool)
+	var cells []int
+	for row := row0 - reach; row <= row0+reach; row++ {
+		for col := col0 - reach; col <= col0+reach; col++ {
+			c, r := col, row
+			if torus {
+				c = (c%cols + cols) % cols
+				r = (r%rows + rows) % rows
+			} else if c < 0 || c >= cols || r < 0 || r >= rows {
+				continue
+			}
+			center := geom.Point{X: (float64(c) + 0.5) * territoryCell, Y: (float64(r) + 0.5) * territoryCell}
+			dx, dy := w.Offset(t.Center, center)
+			cell := r*cols + c
+			if dx*dx+dy*dy > t.Radius*t.Radius || seen[cell] || w.Mobility(center) == 0 {
+				continue
+			}
+			seen[cell] = true
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
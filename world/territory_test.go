@@ -0,0 +1,74 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+)
+
+// TestBeatenIntruderLeaves has a hungry fox forage in the territory of a
+// well-fed one, among rabbits that are worth nothing to eat, and checks
+// that once it loses a fight it leaves instead of staying to be
+// challenged again.
+func TestBeatenIntruderLeaves(t *testing.T) {
+	cfg := config.Default()
+	cfg.World.Width, cfg.World.Height = 200, 200
+	rabbit := cfg.Species["rabbit"]
+	rabbit.NutritionBase, rabbit.NutritionFactor = 0, 0
+	rabbit.MovementSpeed = 0.1
+	rabbit.Flee.DetectionRadius = 0
+	rabbit.Home.Structure = ""
+	cfg.Species["rabbit"] = rabbit
+	w := NewWorld(cfg, 3)
+
+	center := geom.Point{X: 100, Y: 100}
+	owner := w.Spawn("fox", center.X, center.Y).(*entities.Fox)
+	owner.Sex = entities.Male
+	owner.Energy = owner.MaxEnergy
+	owner.Claim = &center
+	owner.Marked = owner.Territory.MarkLife
+	intruder := w.Spawn("fox", center.X+10, center.Y).(*entities.Fox)
+	intruder.Sex = entities.Male
+	intruder.Energy = 150
+	for i := 0; i < 40; i++ {
+		angle := 2 * math.Pi * float64(i) / 40
+		w.Spawn("rabbit", center.X+15*math.Cos(angle), center.Y+15*math.Sin(angle))
+	}
+
+	beaten, fights := -1, 0
+	w.Subscribe(func(e Event) {
+		if f, ok := e.(Fought); ok && (f.Winner == Entity(intruder) || f.Loser == Entity(intruder)) {
+			fights++
+			if beaten < 0 && f.Loser == Entity(intruder) {
+				beaten, fights = w.Tick, 0
+			}
+		}
+	})
+	radius := owner.Territory.Radius
+	left := false
+	for w.Tick < 60 && (beaten < 0 || w.Tick < beaten+25) && !left {
+		w.Update()
+		_, _, distance := intruder.DistanceTo(w, center)
+		left = beaten >= 0 && distance > radius
+	}
+	if beaten < 0 {
+		t.Fatal("the owner never beat the intruder")
+	}
+	if !intruder.IsAlive() {
+		t.Fatalf("the intruder died of %s", intruder.DeathCause)
+	}
+	if !left {
+		_, _, distance := intruder.DistanceTo(w, center)
+		t.Fatalf("25 ticks after losing at tick %d the intruder is %.1f from the territory's centre, within its radius %g",
+			beaten, distance, radius)
+	}
+	if fights > 0 {
+		t.Errorf("the intruder fought %d more times on its way out", fights)
+	}
+	if intruder.Routed {
+		t.Error("the intruder is still routed after leaving the territory")
+	}
+}
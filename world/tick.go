@@ -0,0 +1,226 @@
+package world
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
+)
+
+// A tick has two phases. In the sense phase every entity decides what to
+// do, concurrently and without changing anything, against the spatial
+// index built at the end of the previous tick. In the act phase the world
+// applies the decisions one by one: first each entity's own upkeep and
+// movement, then the eat and mate interactions, in an order that depends
+// only on entity IDs and distances, never on the order of w.Entities.
+
+const (
+	// Below this many entities the sense phase runs on one goroutine.
+	parallelThreshold = 2048
+	decideBatch       = 256
+)
+
+// entityView is the world as an entity sees it while deciding and acting:
+// reads go to the shared world, random draws to a stream of the entity's
+// own, so the outcome depends neither on scheduling nor on the order of
+// w.Entities.
+type entityView struct {
+	*World
+	source *rand.PCG
+	rng    *rand.Rand
+}
+
+func (w *World) newEntityView() *entityView {
+	source := rand.NewPCG(0, 0)
+	return &entityView{World: w, source: source, rng: rand.New(source)}
+}
+
+// seed points the view's random stream at entity's stream for a phase.
+func (v *entityView) seed(phaseSeed uint64, entity Entity) {
+	v.source.Seed(phaseSeed, entity.GetID())
+}
+
+func (v *entityView) Random() *rand.Rand { return v.rng }
+
+func (w *World) decide(current []Entity) []interfaces.Action {
+	actions := make([]interfaces.Action, len(current))
+	tickSeed := w.Rand.Uint64()
+
+	workers := w.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if len(current) < parallelThreshold {
+		workers = 1
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view := w.newEntityView()
+
+			for {
+				start := int(next.Add(decideBatch)) - decideBatch
+				if start >= len(current) {
+					return
+				}
+				end := min(start+decideBatch, len(current))
+
+				for j := start; j < end; j++ {
+					entity := current[j]
+					if !entity.IsAlive() {
+						continue
+					}
+					view.seed(tickSeed, entity)
+					actions[j] = entity.Decide(view)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return actions
+}
+
+type claim struct {
+	actor  Entity
+	action interfaces.Action
+}
+
+// resolve grants eat, mate and fight actions. Claims on the same target
+// are served closest first, ties going to the lower ID, so when two foxes
+// go for one rabbit the nearer one eats it. An animal mates and fights at
+// most once per tick, whether it asked or was asked. Grazing comes last,
+// so an animal eaten this tick does not graze, and follows the same order
+// with field cells as targets.
+func (w *World) resolve(current []Entity, actions []interfaces.Action) {
+	var claims, grazes []claim
+	for i, action := range actions {
+		if !current[i].IsAlive() {
+			continue
+		}
+		if action.Kind == interfaces.Graze {
+			grazes = append(grazes, claim{actor: current[i], action: action})
+		} else if action.Kind != interfaces.Idle && action.Target != nil {
+			claims = append(claims, claim{actor: current[i], action: action})
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		a, b := claims[i], claims[j]
+		if ta, tb := a.action.Target.GetID(), b.action.Target.GetID(); ta != tb {
+			return ta < tb
+		}
+		if a.action.Distance != b.action.Distance {
+			return a.action.Distance < b.action.Distance
+		}
+		return a.actor.GetID() < b.actor.GetID()
+	})
+
+	mated := make(map[uint64]bool)
+	fought := make(map[uint64]bool)
+	for _, c := range claims {
+		actor, target := c.actor, c.action.Target
+		if !actor.IsAlive() || !target.IsAlive() {
+			continue
+		}
+
+		switch c.action.Kind {
+		case interfaces.Eat:
+			// Prey that made it into hiding this tick escapes.
+			if hider, ok := target.(entities.Homing); ok && hider.Hidden() {
+				continue
+			}
+			sick := isInfected(target)
+			energy := w.ConsumeFood(target, actor)
+			if feeder, ok := actor.(interfaces.Feeder); ok {
+				before := held(actor)
+				feeder.Feed(energy)
+				w.Ledger.Eaten += held(actor) - before
+			}
+			if sick {
+				w.catchFromPrey(actor, target)
+			}
+		case interfaces.Mate:
+			initiator, ok1 := actor.(interfaces.Breeder)
+			partner, ok2 := target.(interfaces.Breeder)
+			if !ok1 || !ok2 || mated[actor.GetID()] || mated[target.GetID()] || !initiator.CanReproduce() || !partner.CanReproduce() || !initiator.CanMateWith(target) {
+				continue
+			}
+			mother, father, paternal := w.parents(actor, target)
+			litter := w.litterFor(mother, father, paternal)
+			if litter == 0 {
+				continue
+			}
+			mated[actor.GetID()] = true
+			mated[target.GetID()] = true
+
+			if f := initiator.Fertility() * partner.Fertility(); f >= 1 || w.Rand.Float64() < f {
+				w.conceive(mother, father, litter, paternal)
+			}
+			before := held(actor) + held(target)
+			initiator.Mated()
+			partner.Mated()
+			w.Ledger.Courtship += before - held(actor) - held(target)
+		case interfaces.Fight:
+			owner, ok1 := actor.(entities.Territorial)
+			intruder, ok2 := target.(entities.Territorial)
+			if !ok1 || !ok2 || fought[actor.GetID()] || fought[target.GetID()] {
+				continue
+			}
+			fought[actor.GetID()] = true
+			fought[target.GetID()] = true
+
+			cost := w.Config.Species[actor.GetSpecies()].Territory.FightCost
+			before := held(actor) + held(target)
+			actor.UpdateEnergy(-cost)
+			target.UpdateEnergy(-cost)
+			w.Ledger.Fights += before - held(actor) - held(target)
+
+			winner, loser := actor, target
+			if actor.GetEnergy() < target.GetEnergy() {
+				winner, loser = target, actor
+				owner.Yield()
+			} else {
+				intruder.Rout()
+			}
+			w.emit(Fought{Tick: w.Tick, Winner: winner, Loser: loser})
+		}
+	}
+
+	if len(grazes) > 0 && w.Field != nil {
+		w.resolveGrazing(grazes)
+	}
+}
+
+func (w *World) resolveGrazing(grazes []claim) {
+	sort.Slice(grazes, func(i, j int) bool {
+		a, b := grazes[i], grazes[j]
+		if ca, cb := w.Field.Cell(a.action.At), w.Field.Cell(b.action.At); ca != cb {
+			return ca < cb
+		}
+		if a.action.Distance != b.action.Distance {
+			return a.action.Distance < b.action.Distance
+		}
+		return a.actor.GetID() < b.actor.GetID()
+	})
+
+	for _, c := range grazes {
+		if !c.actor.IsAlive() {
+			continue
+		}
+		energy := w.graze(c.actor, c.action.At)
+		if feeder, ok := c.actor.(interfaces.Feeder); ok {
+			before := held(c.actor)
+			feeder.Feed(energy)
+			w.Ledger.Eaten += held(c.actor) - before
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package world
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestWorkersDoNotChangeRun(t *testing.T) {
+	for name, cfg := range testConfigs() {
+		t.Run(name, func(t *testing.T) {
+			one := newTestWorld(t, cfg(), 11, 1)
+			eight := newTestWorld(t, cfg(), 11, 8)
+			run(one, 150)
+			run(eight, 150)
+			if !bytes.Equal(state(t, one), state(t, eight)) {
+				t.Error("a run on 8 workers differs from one on a single worker")
+			}
+		})
+	}
+}
+
+func TestEntityOrderDoesNotChangeRun(t *testing.T) {
+	for name, cfg := range testConfigs() {
+		t.Run(name, func(t *testing.T) {
+			ordered := newTestWorld(t, cfg(), 12, 4)
+			shuffled := newTestWorld(t, cfg(), 12, 4)
+			rng := rand.New(rand.NewPCG(1, 2))
+			for tick := 0; tick < 150; tick++ {
+				rng.Shuffle(len(shuffled.Entities), func(i, j int) {
+					shuffled.Entities[i], shuffled.Entities[j] = shuffled.Entities[j], shuffled.Entities[i]
+				})
+				ordered.Update()
+				shuffled.Update()
+			}
+			if !bytes.Equal(state(t, ordered), state(t, shuffled)) {
+				t.Error("shuffling the entity list changes the run")
+			}
+		})
+	}
+}
@@ -1,25 +1,63 @@
 package world
 
 import (
-	"math/rand"
+	"math"
+	"math/rand/v2"
+	"sort"
 
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
 	"github.com/j-bisew/foxes-rabbits-simulation/interfaces"
 	"github.com/j-bisew/foxes-rabbits-simulation/geom"
 	"github.com/j-bisew/foxes-rabbits-simulation/quadtree"
 	"github.com/j-bisew/foxes-rabbits-simulation/entities"
+	"github.com/j-bisew/foxes-rabbits-simulation/terrain"
 )
 
 type Entity = interfaces.Entity
 
 type World struct {
 	Width, Height int
-	QuadTree      *quadtree.QuadTree
 	Entities      []Entity
+	index         *spatialIndex
+	// Field holds the grass in field mode and is nil otherwise.
+	Field         *Field
+	// Terrain is the ground under the board; nil means meadow everywhere.
+	Terrain       *terrain.Map
 	GrassSpawnRate    float64
 	MaxGrassCount     int
+
+	Seed uint64
+	Rand *rand.Rand
+	source *rand.PCG
+
+	Config *config.Config
+
+	// Tick counts the updates since the world was populated.
+	Tick      int
+	Recorders []Recorder
+	stats     *TickStats
+	// Ledger accounts for the animals' energy during the last tick.
+	Ledger Ledger
+
+	subscribers      []subscription
+	nextSubscription int
+
+	// Workers is the number of goroutines used to decide entity actions;
+	// zero means one per CPU. Results do not depend on it.
+	Workers int
+	nextID  uint64
+
+	predators map[string][]string
+	// territories lists the territories held at the start and the end of
+	// every tick.
+	territories map[string][]interfaces.Territory
 }
 
-func NewWorld(width, height int) *World {
+// NewWorld creates an empty world sized and tuned by cfg. All random draws
+// come from a source seeded with seed, so equal seeds reproduce equal runs.
+func NewWorld(cfg *config.Config, seed uint64) *World {
+	width, height := cfg.World.Width, cfg.World.Height
+
 	boundary := geom.Rectangle{
 		X: 0, Y: 0,
 		Width: float64(width),
@@ -29,52 +67,102 @@ func NewWorld(width, height int) *World {
 	world := &World{
 		Width:         width,
 		Height:        height,
-		QuadTree:      quadtree.NewQuadTree(10, boundary),
+		index:         newSpatialIndex(boundary),
 		Entities:      make([]Entity, 0),
-		GrassSpawnRate: 0.5,
-		MaxGrassCount: int(float64(width * height) * 0.70),
+		GrassSpawnRate: cfg.World.GrassSpawnRate,
+		MaxGrassCount: int(float64(width * height) * cfg.World.MaxGrassFraction),
+		Config:        cfg,
 	}
+	world.Reseed(seed)
+	world.Subscribe(world.collectStats)
+	world.predators = predatorsByPrey(cfg)
 	
 	return world
 }
 
+// Reseed restarts the world's random source from seed.
+func (w *World) Reseed(seed uint64) {
+	w.Seed = seed
+	w.source = rand.NewPCG(seed, seed)
+	w.Rand = rand.New(w.source)
+}
+
+func (w *World) Random() *rand.Rand { return w.Rand }
+
 // Grass
 func (w *World) SpawnInitialGrassRandom(count int) {
 	for i := 0; i < count; i++ {
-		x := rand.Float64() * float64(w.Width)
-		y := rand.Float64() * float64(w.Height)
-		grass := entities.NewGrass(x, y)
-		w.AddEntity(grass)
+		x := w.Rand.Float64() * float64(w.Width)
+		y := w.Rand.Float64() * float64(w.Height)
+		if grass := w.plantGrass(x, y); grass != nil {
+			w.AddEntity(grass)
+		}
 	}
 }
 
+// plantGrass creates a grass patch suited to the ground at (x, y), or
+// returns nil where nothing grows.
+func (w *World) plantGrass(x, y float64) *entities.Grass {
+	grass := entities.NewGrass(x, y, w.Config.Grass, w.Rand)
+	ground := w.ground(grass.Pos)
+	if ground.GrassCapacity <= 0 {
+		return nil
+	}
+	grass.MaxAmount *= ground.GrassCapacity
+	grass.GrowthRate *= ground.GrassGrowth
+	return grass
+}
+
 func (w *World) spawnGrass() {
-	grassCount := w.CountGrass()
+	if w.Field != nil {
+		// A seed lands on a random cell; bare cells start regrowing from it.
+		if w.Rand.Float64() < w.GrassSpawnRate*w.Climate().GrassSpawn {
+			i := w.Rand.IntN(len(w.Field.Biomass))
+			if w.Field.Biomass[i] == 0 {
+				w.Field.Biomass[i] = w.Field.Regrowth[i]
+			}
+		}
+		return
+	}
+
+	grassCount := w.Count("grass")
 	if grassCount >= w.MaxGrassCount {
 		return
 	}
 	
-	if rand.Float64() < w.GrassSpawnRate {
-		x := rand.Float64() * float64(w.Width)
-		y := rand.Float64() * float64(w.Height)
-		grass := entities.NewGrass(x, y)
-		w.AddEntity(grass)
+	if w.Rand.Float64() < w.GrassSpawnRate*w.Climate().GrassSpawn {
+		x := w.Rand.Float64() * float64(w.Width)
+		y := w.Rand.Float64() * float64(w.Height)
+		if grass := w.plantGrass(x, y); grass != nil {
+			w.AddEntity(grass)
+			w.emit(GrassSpawned{Tick: w.Tick, Grass: grass})
+		}
 	}
 }
 
 // Adders
+// AddEntity gives entity a fresh ID unless it already has one, e.g. from
+// a snapshot, and indexes it.
 func (w *World) AddEntity(entity Entity) {
+	if entity.GetID() == 0 {
+		w.nextID++
+		entity.SetID(w.nextID)
+	} else if entity.GetID() > w.nextID {
+		w.nextID = entity.GetID()
+	}
 	w.Entities = append(w.Entities, entity)
+	w.index.Insert(entity)
 }
 
-func (w *World) AddRabbit(x, y float64) {
-	rabbit := entities.NewRabbit(x, y)
-	w.AddEntity(rabbit)
-}
-
-func (w *World) AddFox(x, y float64) {
-	fox := entities.NewFox(x, y)
-	w.AddEntity(fox)
+// Spawn creates a member of a registered species at the given position.
+func (w *World) Spawn(species string, x, y float64) Entity {
+	info, ok := entities.LookupSpecies(species)
+	if !ok {
+		return nil
+	}
+	entity := info.New(x, y, w.Config, w.Rand)
+	w.AddEntity(entity)
+	return entity
 }
 
 // Cleaner
@@ -83,6 +171,9 @@ func (w *World) removeDeadEntities() {
 	for _, entity := range w.Entities {
 		if entity.IsAlive() {
 			alive = append(alive, entity)
+		} else {
+			w.index.Remove(entity)
+			w.Ledger.Died += held(entity)
 		}
 	}
 	w.Entities = alive
@@ -90,56 +181,425 @@ func (w *World) removeDeadEntities() {
 
 func (w *World) ClearEntities() {
 	w.Entities = w.Entities[:0]
-	w.QuadTree.Clear()
+	w.index.Clear()
+	w.Field = nil
+	w.Tick = 0
+	w.nextID = 0
+	w.territories = nil
+}
+
+// Reset empties the world, restores the spawn settings used for a new run
+// and restarts the random source from the world's seed.
+func (w *World) Reset() {
+	w.ClearEntities()
+	w.Reseed(w.Seed)
+
+	totalCells := w.Width * w.Height
+	w.MaxGrassCount = int(float64(totalCells) * w.Config.World.MaxGrassFraction)
+	w.GrassSpawnRate = w.Config.World.GrassSpawnRate
+}
+
+// Populate seeds the world with grass covering the given share of the board
+// (in basis points, capped at MaxGrassCount), the structures at the places
+// the config lists and randomly placed structures and animals, counts
+// giving the number of each per species. In field mode the share is the
+// fraction of cells that start fully grown.
+func (w *World) Populate(grassPercentageBasisPoints int, counts map[string]int) {
+	totalCells := w.Width * w.Height
+	requestedGrass := int(float64(totalCells) * float64(grassPercentageBasisPoints) / 10000.0)
+
+	grassCount := requestedGrass
+	if grassCount > w.MaxGrassCount {
+		grassCount = w.MaxGrassCount
+	}
+
+	if w.Config.Grass.Mode == config.GrassField {
+		w.Field = newField(w.Width, w.Height, w.Config.Grass, w.Config.World.Boundary == config.BoundaryTorus, w.Rand)
+		for i := range w.Field.Capacity {
+			ground := w.ground(w.Field.Center(i))
+			w.Field.Capacity[i] *= ground.GrassCapacity
+			w.Field.Regrowth[i] *= ground.GrassGrowth
+		}
+		w.Field.Seed(float64(grassCount)/float64(totalCells), w.Rand)
+	} else {
+		w.SpawnInitialGrassRandom(grassCount)
+	}
+
+	// Structures come first, so that animals start out drawn on top.
+	for _, place := range w.Config.Structures.Places {
+		w.Spawn(place.Kind, place.X, place.Y)
+	}
+	for _, info := range append(entities.StructureKinds(), entities.AnimalSpecies()...) {
+		for i := 0; i < counts[info.Name]; i++ {
+			x, y := w.passablePosition()
+			w.Spawn(info.Name, x, y)
+		}
+	}
+}
+
+// passablePosition draws random positions until one is passable, giving up
+// after a while on boards that are almost all water or rock.
+func (w *World) passablePosition() (x, y float64) {
+	for try := 0; try < 100; try++ {
+		x = w.Rand.Float64() * float64(w.Width)
+		y = w.Rand.Float64() * float64(w.Height)
+		if w.Mobility(geom.Point{X: x, Y: y}) > 0 {
+			break
+		}
+	}
+	return x, y
 }
 
 // Reproduction
-func (w *World) CreateOffspring(parent1, parent2 Entity) Entity {
-	newX := (parent1.GetPosition().X + parent2.GetPosition().X) / 2
-	newY := (parent1.GetPosition().Y + parent2.GetPosition().Y) / 2
-	
-	newX += (rand.Float64() - 0.5) * 10.0
-	newY += (rand.Float64() - 0.5) * 10.0
-	
-	if newX < 0 { newX = 0 }
-	if newX >= float64(w.Width) { newX = float64(w.Width - 1) }
-	if newY < 0 { newY = 0 }
-	if newY >= float64(w.Height) { newY = float64(w.Height - 1) }
-	
-	var offspring Entity
-	switch parent1.GetSpecies() {
-	case "rabbit":
-		offspring = entities.NewRabbit(newX, newY)
-	case "fox":
-		offspring = entities.NewFox(newX, newY)
-	default:
-		return nil
+
+// bear places a newborn of mother's species holding energy near at, or
+// at the mother when that ground is impassable. The young shares its
+// mother's home.
+func (w *World) bear(mother Entity, at geom.Point, energy float64) Entity {
+	spread := w.Config.World.OffspringSpread
+	at.X += (w.Rand.Float64() - 0.5) * spread
+	at.Y += (w.Rand.Float64() - 0.5) * spread
+	pos := w.Confine(at)
+	if w.Mobility(pos) == 0 {
+		pos = mother.GetPosition()
 	}
 
-	w.AddEntity(offspring)
+	offspring := w.Spawn(mother.GetSpecies(), pos.X, pos.Y)
+	if offspring == nil {
+		return nil
+	}
+	if young, ok := offspring.(entities.Ageing); ok {
+		young.SetAge(0)
+	}
+	if young, ok := offspring.(entities.Provisioned); ok {
+		young.SetEnergy(energy)
+	}
+	if young, ok := offspring.(entities.Territorial); ok {
+		young.BornAt(pos)
+	}
+	if parent, ok := mother.(entities.Homing); ok {
+		if nest, ok := parent.GetNest(); ok {
+			if young, ok := offspring.(entities.Homing); ok {
+				young.MoveIn(w, nest)
+			}
+		}
+	}
 	return offspring
 }
 
+// parents orders a mating pair as mother and father and returns the
+// share of the young's energy the father pays; without sexes the two
+// pay half each.
+func (w *World) parents(a, b Entity) (mother, father Entity, paternal float64) {
+	mother, father = a, b
+	if bearer, ok := a.(entities.Bearer); ok && bearer.GetSex() == entities.Male {
+		mother, father = b, a
+	}
+	if _, ok := mother.(entities.Bearer); !ok {
+		return mother, father, 0.5
+	}
+	return mother, father, w.Config.Species[mother.GetSpecies()].Reproduction.PaternalShare
+}
+
+// litterFor draws the size of the litter a mother would bear and cuts it
+// down to what both parents can pay for; zero means they cannot afford a
+// single young. Animals without sexes have one young at a time.
+func (w *World) litterFor(mother, father Entity, paternal float64) int {
+	cfg := w.Config.Species[mother.GetSpecies()]
+	litter := 1
+	if _, ok := mother.(entities.Bearer); ok {
+		litter = drawLitter(cfg.Reproduction, w.Rand)
+	}
+	young := cfg.Reproduction.OffspringEnergy
+	for litter > 0 {
+		cost := float64(litter) * young
+		if affords(mother, cfg, cost*(1-paternal)) && affords(father, cfg, cost*paternal) {
+			break
+		}
+		litter--
+	}
+	return litter
+}
+
+func drawLitter(r config.Reproduction, rng *rand.Rand) int {
+	if len(r.LitterWeights) == 0 {
+		return r.LitterMin + rng.IntN(r.LitterMax-r.LitterMin+1)
+	}
+	total := 0.0
+	for _, weight := range r.LitterWeights {
+		total += weight
+	}
+	pick := rng.Float64() * total
+	for i, weight := range r.LitterWeights {
+		if pick < weight {
+			return r.LitterMin + i
+		}
+		pick -= weight
+	}
+	return r.LitterMax
+}
+
+// affords reports whether parent can pay cost for its young and still
+// have energy left after the mating cost.
+func affords(parent Entity, cfg config.Species, cost float64) bool {
+	return parent.GetEnergy()-cfg.MatingEnergyCost-cost > 0
+}
+
+// conceive starts a litter in the mother. The father pays his share of
+// the young's energy now, the mother hers over the gestation, or at once
+// if there is none. Animals without sexes pay the same shares and have
+// their young right away.
+func (w *World) conceive(mother, father Entity, litter int, paternal float64) {
+	cfg := w.Config.Species[mother.GetSpecies()].Reproduction
+	cost := float64(litter) * cfg.OffspringEnergy
+	paid := cost * paternal
+	father.UpdateEnergy(-paid)
+
+	bearer, ok := mother.(entities.Bearer)
+	if !ok {
+		mother.UpdateEnergy(paid - cost)
+		w.emit(Mated{Tick: w.Tick, Parents: [2]Entity{mother, father}})
+		for i := 0; i < litter; i++ {
+			offspring := w.bear(mother, mother.GetPosition(), cfg.OffspringEnergy)
+			if offspring == nil {
+				break
+			}
+			m, ok1 := mother.(entities.Heritable)
+			f, ok2 := father.(entities.Heritable)
+			if ok1 && ok2 {
+				w.inherit(offspring, m.GetGenome(), f.GetGenome())
+			}
+			w.emit(Born{Tick: w.Tick, Entity: offspring, Parents: [2]Entity{mother, father}})
+		}
+		return
+	}
+
+	p := entities.Pregnancy{Litter: litter, Remaining: cfg.Gestation, Provision: paid}
+	if heritable, ok := father.(entities.Heritable); ok {
+		p.Father = heritable.GetGenome()
+	} else if heritable, ok := mother.(entities.Heritable); ok {
+		p.Father = heritable.GetGenome()
+	}
+	if maternal := cost - paid; cfg.Gestation > 0 {
+		p.Rate = maternal / float64(cfg.Gestation)
+	} else {
+		mother.UpdateEnergy(-maternal)
+		p.Provision += maternal
+	}
+	bearer.Conceive(p)
+	w.emit(Mated{Tick: w.Tick, Parents: [2]Entity{mother, father}})
+}
+
+// deliver brings the litters that have come to term into the world,
+// mothers in ID order.
+func (w *World) deliver() {
+	var mothers []Entity
+	for _, entity := range w.Entities {
+		if bearer, ok := entity.(entities.Bearer); ok && entity.IsAlive() && bearer.Pregnant() {
+			mothers = append(mothers, entity)
+		}
+	}
+	sort.Slice(mothers, func(i, j int) bool { return mothers[i].GetID() < mothers[j].GetID() })
+
+	for _, mother := range mothers {
+		pregnancy, ok := mother.(entities.Bearer).Deliver()
+		if !ok {
+			continue
+		}
+		genome := pregnancy.Father
+		if heritable, ok := mother.(entities.Heritable); ok {
+			genome = heritable.GetGenome()
+		}
+		energy := pregnancy.Provision / float64(pregnancy.Litter)
+		for i := 0; i < pregnancy.Litter; i++ {
+			offspring := w.bear(mother, mother.GetPosition(), energy)
+			if offspring == nil {
+				break
+			}
+			w.inherit(offspring, genome, pregnancy.Father)
+			w.emit(Born{Tick: w.Tick, Entity: offspring, Parents: [2]Entity{mother, nil}})
+		}
+	}
+}
+
+// inherit gives a newborn a genome crossed over from both parents'.
+func (w *World) inherit(offspring Entity, mother, father entities.Genome) {
+	child, ok := offspring.(entities.Heritable)
+	if !ok {
+		return
+	}
+	cfg := w.Config.Species[offspring.GetSpecies()]
+	child.Express(entities.Crossover(mother, father, cfg, w.Rand), cfg)
+}
+
 // Methods for Entities
 func (w *World) FindNearbyEntities(pos geom.Point, radius float64, species string) []Entity {
-	searchRect := geom.Rectangle{
-		X: pos.X - radius,
-		Y: pos.Y - radius,
-		Width: radius * 2,
-		Height: radius * 2,
+	qt := w.index.tree(species)
+	if qt == nil {
+		return nil
 	}
-	
+	filter := quadtree.And(quadtree.Alive, quadtree.EnergyAbove(0))
+
 	var nearbyEntities []Entity
-	w.QuadTree.Query(searchRect, &nearbyEntities)
-	
-	var filtered []Entity
+	centers := w.SearchCenters(pos, radius)
+	for _, center := range centers {
+		qt.QueryCircle(center, radius, filter, &nearbyEntities)
+	}
+	if len(centers) == 1 {
+		return nearbyEntities
+	}
+
+	// On a small torus the shifted circles can overlap.
+	seen := make(map[Entity]bool)
+	var unique []Entity
 	for _, entity := range nearbyEntities {
-		if entity.GetSpecies() == species && entity.IsAlive() && entity.GetEnergy() > 0{
-			filtered = append(filtered, entity)
+		if !seen[entity] {
+			seen[entity] = true
+			unique = append(unique, entity)
 		}
 	}
-	
-	return filtered
+	return unique
+}
+
+// FindNearest returns up to k entities of species within radius of pos
+// that pass filter, closest first and by ID at equal distance.
+func (w *World) FindNearest(pos geom.Point, radius float64, k int, species string, filter func(Entity) bool) []Entity {
+	qt := w.index.tree(species)
+	if qt == nil {
+		return nil
+	}
+	centers := w.SearchCenters(pos, radius)
+
+	var neighbors []quadtree.Neighbor
+	for _, center := range centers {
+		neighbors = append(neighbors, qt.NearestWithin(center, k, radius, filter)...)
+	}
+	if len(centers) > 1 {
+		sort.Slice(neighbors, func(i, j int) bool {
+			if neighbors[i].Distance != neighbors[j].Distance {
+				return neighbors[i].Distance < neighbors[j].Distance
+			}
+			return neighbors[i].Entity.GetID() < neighbors[j].Entity.GetID()
+		})
+	}
+
+	seen := make(map[Entity]bool, len(neighbors))
+	found := make([]Entity, 0, min(k, len(neighbors)))
+	for _, n := range neighbors {
+		if len(found) == k {
+			break
+		}
+		if !seen[n.Entity] {
+			seen[n.Entity] = true
+			found = append(found, n.Entity)
+		}
+	}
+	return found
+}
+
+// LoadTerrain builds the terrain the config asks for, generating it from
+// the world's seed or reading it from a map file.
+func (w *World) LoadTerrain() error {
+	m, err := terrain.FromConfig(w.Config.Terrain, w.Width, w.Height, w.Seed)
+	if err != nil {
+		return err
+	}
+	w.Terrain = m
+	return nil
+}
+
+// ground returns the settings of the terrain at p.
+func (w *World) ground(p geom.Point) config.TerrainType {
+	if w.Terrain == nil {
+		return w.Config.Terrain.Types.Meadow
+	}
+	kind := w.Terrain.At(p.X/float64(w.Width), p.Y/float64(w.Height))
+	return kind.Params(w.Config.Terrain.Types)
+}
+
+// Mobility returns the speed factor of the ground at p, zero where
+// animals cannot go.
+func (w *World) Mobility(p geom.Point) float64 {
+	ground := w.ground(p)
+	if !ground.Passable {
+		return 0
+	}
+	return ground.Speed
+}
+
+// predatorsByPrey maps each species to the species that eat it, in name
+// order.
+func predatorsByPrey(cfg *config.Config) map[string][]string {
+	predators := make(map[string][]string)
+	for name, species := range cfg.Species {
+		for _, food := range species.Diet {
+			predators[food] = append(predators[food], name)
+		}
+	}
+	for _, list := range predators {
+		sort.Strings(list)
+	}
+	return predators
+}
+
+func (w *World) PredatorsOf(species string) []string {
+	return w.predators[species]
+}
+
+// FindPasture returns the centre of the closest field cell within radius
+// of pos that holds at least a bite. ok is false outside field mode or
+// when species is not what the field grows.
+func (w *World) FindPasture(pos geom.Point, radius float64, species string) (spot geom.Point, ok bool) {
+	f := w.Field
+	if f == nil || species != fieldSpecies {
+		return geom.Point{}, false
+	}
+
+	bite := w.Config.Grass.BiteMin
+	reach := int(math.Ceil(radius/f.CellSize)) + 1
+	here := f.Cell(pos)
+	col0, row0 := here%f.Cols, here/f.Cols
+
+	best, bestDistance := -1, 0.0
+	for row := row0 - reach; row <= row0+reach; row++ {
+		for col := col0 - reach; col <= col0+reach; col++ {
+			c, r := col, row
+			if f.Torus {
+				c = (c%f.Cols + f.Cols) % f.Cols
+				r = (r%f.Rows + f.Rows) % f.Rows
+			} else if c < 0 || c >= f.Cols || r < 0 || r >= f.Rows {
+				continue
+			}
+			i := r*f.Cols + c
+			if f.Biomass[i] < bite {
+				continue
+			}
+			dx, dy := w.Offset(pos, f.Center(i))
+			distance := math.Sqrt(dx*dx + dy*dy)
+			if distance > radius {
+				continue
+			}
+			if best < 0 || distance < bestDistance || (distance == bestDistance && i < best) {
+				best, bestDistance = i, distance
+			}
+		}
+	}
+	if best < 0 {
+		return geom.Point{}, false
+	}
+	return f.Center(best), true
+}
+
+// graze lets eater take a bite from the field cell at spot.
+func (w *World) graze(eater Entity, spot geom.Point) float64 {
+	if w.Field == nil {
+		return 0.0
+	}
+	want := w.Config.Grass.BiteMin + w.Rand.Float64()*(w.Config.Grass.BiteMax-w.Config.Grass.BiteMin)
+	energy := w.Field.Graze(spot, want)
+
+	w.emit(Grazed{Tick: w.Tick, Eater: eater, At: spot, Energy: energy})
+	return energy
 }
 
 func (w *World) IsValidPosition(x, y float64) bool {
@@ -147,64 +607,154 @@ func (w *World) IsValidPosition(x, y float64) bool {
 }
 
 func (w *World) ConsumeFood(food Entity, eater Entity) float64 {
-	switch food.GetSpecies() {
-	case "grass":
-		grass := food.(*entities.Grass)
-		wantedAmount := 20.0 + rand.Float64()*20.0
-		if grass.GetEnergy() < wantedAmount {
-			return grass.Consume(grass.GetEnergy())
-		}
-		return grass.Consume(wantedAmount)
-	case "rabbit":
-		energyGain := 80.0 + (food.GetEnergy() * 0.3)
-		food.Kill()
-		return energyGain
+	info, ok := entities.LookupSpecies(food.GetSpecies())
+	if !ok || info.Eaten == nil {
+		return 0.0
 	}
-	return 0.0
+	energy := info.Eaten(food, w.Config, w.Rand)
+
+	w.emit(Ate{Tick: w.Tick, Eater: eater, Food: food, Energy: energy})
+	w.emitIfDied(food)
+	return energy
 }
 
 // Main Method
+
+// Update advances the world by one tick. The spatial index is kept up to
+// date incrementally as entities are born, move and die.
 func (w *World) Update() {
-	w.QuadTree.Clear()
-	
-	for _, entity := range w.Entities {
+	w.Tick++
+	if len(w.Recorders) > 0 {
+		w.stats = newTickStats(w.Tick)
+	}
+
+	// Offspring born during this tick are appended to w.Entities but act
+	// from the next tick on.
+	w.outbreak()
+	w.mapTerritories()
+	current := w.Entities
+	w.Ledger.open(current)
+	actions := w.decide(current)
+
+	actSeed := w.Rand.Uint64()
+	view := w.newEntityView()
+	for i, entity := range current {
 		if entity.IsAlive() {
-			entity.Update(w)
-			w.QuadTree.Insert(entity.GetPosition(), entity)
+			from := entity.GetPosition()
+			view.seed(actSeed, entity)
+			entity.Act(view, actions[i])
+			if to := entity.GetPosition(); to != from {
+				w.index.Move(from, to, entity)
+			}
+			w.emitIfDied(entity)
 		}
 	}
-	
+	w.resolve(current, actions)
+	w.deliver()
+	w.spread()
+
 	w.removeDeadEntities()
+	w.mapTerritories()
 	w.spawnGrass()
+	if w.Field != nil {
+		w.Field.Update(w.Climate().GrassGrowth)
+	}
+	w.Ledger.close(w.Entities)
+
+	if w.stats != nil {
+		w.stats.Energy = w.Ledger
+		w.stats.Season = w.Season().String()
+		w.stats.Night = w.IsNight()
+		w.stats.finish(w.Entities)
+		w.addGroups(w.stats)
+		w.addTerritories(w.stats)
+		if w.Field != nil {
+			w.stats.addField(w.Field, w.Config.Grass.BiteMin)
+		}
+		for _, r := range w.Recorders {
+			r.Record(w.stats)
+		}
+		w.stats = nil
+	}
 }
 
 // Getters for stats
-func (w *World) CountRabbits() int {
+func (w *World) Count(species string) int {
 	count := 0
 	for _, entity := range w.Entities {
-		if entity.GetSpecies() == "rabbit" && entity.IsAlive() {
+		if entity.GetSpecies() == species && entity.IsAlive() {
 			count++
 		}
 	}
 	return count
 }
 
-func (w *World) CountFoxes() int {
-	count := 0
+// Populations counts the living members of every registered species. In
+// field mode grass is counted as the cells holding at least a bite.
+func (w *World) Populations() map[string]int {
+	counts := make(map[string]int)
+	for _, info := range entities.AllSpecies() {
+		counts[info.Name] = 0
+	}
 	for _, entity := range w.Entities {
-		if entity.GetSpecies() == "fox" && entity.IsAlive() {
-			count++
+		if entity.IsAlive() {
+			counts[entity.GetSpecies()]++
 		}
 	}
-	return count
+	if w.Field != nil {
+		counts[fieldSpecies] = w.Field.Grazable(w.Config.Grass.BiteMin)
+	}
+	return counts
 }
 
-func (w *World) CountGrass() int {
-	count := 0
+// TraitStats summarizes the genomes of one species.
+type TraitStats struct {
+	Count    int
+	Mean     entities.Genome
+	Variance entities.Genome
+}
+
+// TraitStats returns the mean and population variance of every heritable
+// trait for each species with living members.
+func (w *World) TraitStats() map[string]TraitStats {
+	sums := make(map[string][]float64)
+	squares := make(map[string][]float64)
+	counts := make(map[string]int)
+
 	for _, entity := range w.Entities {
-		if entity.GetSpecies() == "grass" && entity.IsAlive() {
-			count++
+		animal, ok := entity.(entities.Heritable)
+		if !ok || !entity.IsAlive() {
+			continue
+		}
+		species := entity.GetSpecies()
+		traits := animal.GetGenome().Traits()
+		if sums[species] == nil {
+			sums[species] = make([]float64, len(traits))
+			squares[species] = make([]float64, len(traits))
+		}
+		for i, t := range traits {
+			sums[species][i] += t
+			squares[species][i] += t * t
 		}
+		counts[species]++
 	}
-	return count
+
+	stats := make(map[string]TraitStats, len(counts))
+	for species, n := range counts {
+		mean := make([]float64, len(sums[species]))
+		variance := make([]float64, len(sums[species]))
+		for i := range mean {
+			mean[i] = sums[species][i] / float64(n)
+			variance[i] = squares[species][i]/float64(n) - mean[i]*mean[i]
+			if variance[i] < 0 {
+				variance[i] = 0
+			}
+		}
+		stats[species] = TraitStats{
+			Count:    n,
+			Mean:     entities.GenomeFromTraits(mean),
+			Variance: entities.GenomeFromTraits(variance),
+		}
+	}
+	return stats
 }
\ No newline at end of file
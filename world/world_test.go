@@ -0,0 +1,153 @@
+package world
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/j-bisew/foxes-rabbits-simulation/config"
+	"github.com/j-bisew/foxes-rabbits-simulation/geom"
+)
+
+// busyConfig keeps foxes alive long enough to hunt, breed and fight over
+// territories, and starts an outbreak among the rabbits.
+func busyConfig() *config.Config {
+	cfg := config.Default()
+	cfg.World.Width, cfg.World.Height = 200, 100
+	fox := cfg.Species["fox"]
+	fox.EnergyLoss = 1.2
+	fox.CriticalHungerThreshold = 150
+	cfg.Species["fox"] = fox
+	cfg.Outbreak = config.Outbreak{Tick: 20, Species: "rabbit", Count: 4}
+	return cfg
+}
+
+// testConfigs are the set-ups the run-level tests go through.
+func testConfigs() map[string]func() *config.Config {
+	return map[string]func() *config.Config{
+		"default": busyConfig,
+		"field torus": func() *config.Config {
+			cfg := busyConfig()
+			cfg.Grass.Mode = config.GrassField
+			cfg.World.Boundary = config.BoundaryTorus
+			return cfg
+		},
+		"terrain reflect": func() *config.Config {
+			cfg := busyConfig()
+			cfg.Terrain.Map = config.TerrainNoise
+			cfg.World.Boundary = config.BoundaryReflect
+			return cfg
+		},
+	}
+}
+
+// newTestWorld populates a world the way a headless run does.
+func newTestWorld(t *testing.T, cfg *config.Config, seed uint64, workers int) *World {
+	t.Helper()
+	w := NewWorld(cfg, seed)
+	if err := w.LoadTerrain(); err != nil {
+		t.Fatal(err)
+	}
+	w.Workers = workers
+	w.Reset()
+	w.Populate(3000, map[string]int{"rabbit": 40, "fox": 10, "burrow": 5, "den": 3})
+	return w
+}
+
+// run advances w by ticks updates.
+func run(w *World, ticks int) {
+	for i := 0; i < ticks; i++ {
+		w.Update()
+	}
+}
+
+// state is the snapshot of w with its entities in ID order, so that it
+// does not depend on the order of the entity list.
+func state(t *testing.T, w *World) []byte {
+	t.Helper()
+	byID(w.Entities)
+	var buf bytes.Buffer
+	if err := w.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSameSeedSameRun(t *testing.T) {
+	for name, cfg := range testConfigs() {
+		t.Run(name, func(t *testing.T) {
+			a := newTestWorld(t, cfg(), 7, 1)
+			b := newTestWorld(t, cfg(), 7, 1)
+			c := newTestWorld(t, cfg(), 8, 1)
+			run(a, 150)
+			run(b, 150)
+			run(c, 150)
+			if !bytes.Equal(state(t, a), state(t, b)) {
+				t.Error("two runs with the same seed differ")
+			}
+			if bytes.Equal(state(t, a), state(t, c)) {
+				t.Error("runs with different seeds are identical")
+			}
+		})
+	}
+}
+
+func TestFindNearestLargeK(t *testing.T) {
+	w := boundaryWorld(config.BoundaryTorus)
+	var want []Entity
+	// Closest first, alternating sides of the wrapped edge.
+	for _, x := range []float64{99, 1, 97, 3} {
+		want = append(want, w.Spawn("rabbit", x, 25))
+	}
+	w.Spawn("rabbit", 50, 10)
+
+	found := w.FindNearest(geom.Point{X: 99.5, Y: 25}, 5, 1<<30, "rabbit", nil)
+	if len(found) != len(want) {
+		t.Fatalf("found %d rabbits, want %d", len(found), len(want))
+	}
+	for i := range want {
+		if found[i] != want[i] {
+			t.Fatalf("rabbit %d found is at %v, want the one at %v", i, found[i].GetPosition(), want[i].GetPosition())
+		}
+	}
+	if cap(found) > len(want) {
+		t.Fatalf("a large k reserved room for %d rabbits, want at most %d", cap(found), len(want))
+	}
+}
+
+// TestDefaultFoxesHunt runs the defaults the way a headless run seeds them
+// and checks that prey defences leave the foxes able to feed and breed.
+func TestDefaultFoxesHunt(t *testing.T) {
+	ate, born := 0, 0
+	for seed := uint64(1); seed <= 3; seed++ {
+		cfg := config.Default()
+		w := NewWorld(cfg, seed)
+		counts := map[string]int{"rabbit": 20, "fox": 5}
+		for kind, count := range cfg.Structures.Counts {
+			counts[kind] = count
+		}
+		w.Reset()
+		w.Populate(3000, counts)
+		w.Subscribe(func(e Event) {
+			switch e := e.(type) {
+			case Ate:
+				if e.Eater.GetSpecies() == "fox" {
+					ate++
+				}
+			case Born:
+				if e.Entity.GetSpecies() == "fox" {
+					born++
+				}
+			}
+		})
+		run(w, 300)
+		if w.Populations()["fox"] == 0 {
+			t.Errorf("seed %d: foxes died out by tick %d", seed, w.Tick)
+		}
+	}
+	if ate < 30 {
+		t.Errorf("foxes ate %d rabbits in three runs, want at least 30", ate)
+	}
+	if born == 0 {
+		t.Error("no fox was born in three runs")
+	}
+}